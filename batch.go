@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"fifteen/puzzle"
+)
+
+// Output formats of the batch mode.
+const (
+	batchCSV   = "csv"
+	batchJSONL = "jsonl"
+)
+
+// batchRow is the result of one puzzle of the batch.
+type batchRow struct {
+	Line      int     `json:"line"`
+	Puzzle    string  `json:"puzzle"`
+	Solvable  bool    `json:"solvable"`
+	Solved    bool    `json:"solved"`
+	Length    int     `json:"length"`
+	Moves     string  `json:"moves"`
+	Generated int     `json:"generated"`
+	Expanded  int     `json:"expanded"`
+	TimeMs    float64 `json:"time_ms"`
+	Quality   string  `json:"quality,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// batchSummary holds the aggregate totals written after the rows.
+type batchSummary struct {
+	Puzzles     int     `json:"puzzles"`
+	Solvable    int     `json:"solvable"`
+	Unsolvable  int     `json:"unsolvable"`
+	Solved      int     `json:"solved"`
+	Errors      int     `json:"errors"`
+	TotalLength int     `json:"total_length"`
+	Generated   int     `json:"generated"`
+	TimeMs      float64 `json:"time_ms"`
+	AvgLength   float64 `json:"avg_length"`
+	AvgTimeMs   float64 `json:"avg_time_ms"`
+}
+
+// add accumulates a row into the summary.
+func (s *batchSummary) add(row batchRow) {
+	s.Puzzles++
+	if !row.Solvable {
+		// Lines that could not be parsed carry an error; the rest are unsolvable puzzles.
+		if row.Error != "" {
+			s.Errors++
+		} else {
+			s.Unsolvable++
+		}
+		return
+	}
+	s.Solvable++
+	if row.Solved {
+		s.Solved++
+		s.TotalLength += row.Length
+	}
+	s.Generated += row.Generated
+	s.TimeMs += row.TimeMs
+}
+
+// exitCode returns the exit code of the batch: the code of a single solve for the worst
+// row, with lines that could not be read first, then unsolvable and unsolved puzzles.
+func (s batchSummary) exitCode() int {
+	switch {
+	case s.Errors > 0:
+		return exitError
+	case s.Unsolvable > 0:
+		return exitUnsolvable
+	case s.Solved < s.Solvable:
+		return exitNotSolved
+	}
+	return exitOK
+}
+
+// finish computes the averages over the solved puzzles.
+func (s *batchSummary) finish() {
+	if s.Solved > 0 {
+		s.AvgLength = float64(s.TotalLength) / float64(s.Solved)
+	}
+	if s.Solvable > 0 {
+		s.AvgTimeMs = s.TimeMs / float64(s.Solvable)
+	}
+}
+
+// solveBatchLine parses and solves one line of the batch input.
+func solveBatchLine(ctx context.Context, number int, line string) batchRow {
+	row := batchRow{Line: number, Puzzle: strings.Join(strings.Fields(line), " ")}
+	initial, err := puzzle.ParseBoard(line, boardRows, boardCols)
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	result, err := puzzle.Solve(ctx, initial, options)
+	if errors.Is(err, puzzle.ErrUnsolvable) {
+		return row
+	}
+	row.Solvable = true
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	row.Solved = result.Solved
+	row.Generated = result.Generated
+	row.Expanded = result.Expanded
+	row.TimeMs = float64(result.Elapsed) / float64(time.Millisecond)
+	if result.Solved {
+		row.Length = result.Length
+		row.Moves = puzzle.FormatMetricMoves(result.Moves, notation, options.Metric)
+		row.Quality = result.Quality
+	} else {
+		row.Error = result.Stopped
+	}
+	return row
+}
+
+// runBatch solves every puzzle of a file (one per line, "-" reads stdin) with the selected
+// algorithm and heuristic and writes one result row per puzzle followed by the totals,
+// which are also returned.
+func runBatch(ctx context.Context, fileName, format string) (batchSummary, error) {
+	var input io.Reader = os.Stdin
+	if fileName != "-" {
+		file, err := os.Open(fileName)
+		if err != nil {
+			return batchSummary{}, fmt.Errorf("error opening %s: %w", fileName, err)
+		}
+		defer file.Close()
+		input = file
+	}
+	return runBatchReader(ctx, input, fileName, format)
+}
+
+// runBatchReader is runBatch over an already opened input; name is only used in errors.
+// Empty lines and lines starting with '#' are skipped. The heuristic tables are loaded
+// once and shared by all the puzzles.
+func runBatchReader(ctx context.Context, input io.Reader, name, format string) (batchSummary, error) {
+	var summary batchSummary
+	quiet = true
+	if err := prepareTables(); err != nil {
+		return summary, err
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	writeRow, writeSummary := batchWriters(out, format)
+
+	scanner := bufio.NewScanner(input)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		row := solveBatchLine(ctx, number, line)
+		summary.add(row)
+		if err := writeRow(row); err != nil {
+			return summary, err
+		}
+		out.Flush()
+	}
+	if err := scanner.Err(); err != nil {
+		return summary, fmt.Errorf("error reading %s: %w", name, err)
+	}
+
+	summary.finish()
+	return summary, writeSummary(summary)
+}
+
+// batchWriters returns the functions that write a row and the summary in the given format.
+// In CSV the summary is written as '#' comment lines after the rows.
+func batchWriters(out io.Writer, format string) (func(batchRow) error, func(batchSummary) error) {
+	if format == batchJSONL {
+		encoder := json.NewEncoder(out)
+		writeRow := func(row batchRow) error { return encoder.Encode(row) }
+		writeSummary := func(summary batchSummary) error {
+			return encoder.Encode(map[string]batchSummary{"summary": summary})
+		}
+		return writeRow, writeSummary
+	}
+
+	writer := csv.NewWriter(out)
+	header := false
+	writeRow := func(row batchRow) error {
+		if !header {
+			header = true
+			writer.Write([]string{"line", "puzzle", "solvable", "solved", "length", "moves",
+				"generated", "expanded", "time_ms", "quality", "error"})
+		}
+		writer.Write([]string{
+			strconv.Itoa(row.Line),
+			row.Puzzle,
+			strconv.FormatBool(row.Solvable),
+			strconv.FormatBool(row.Solved),
+			strconv.Itoa(row.Length),
+			row.Moves,
+			strconv.Itoa(row.Generated),
+			strconv.Itoa(row.Expanded),
+			strconv.FormatFloat(row.TimeMs, 'f', 3, 64),
+			row.Quality,
+			row.Error,
+		})
+		writer.Flush()
+		return writer.Error()
+	}
+	writeSummary := func(summary batchSummary) error {
+		_, err := fmt.Fprintf(out, "# puzzles=%d solvable=%d unsolvable=%d solved=%d errors=%d\n"+
+			"# total_length=%d generated=%d time_ms=%.3f avg_length=%.2f avg_time_ms=%.3f\n",
+			summary.Puzzles, summary.Solvable, summary.Unsolvable, summary.Solved, summary.Errors,
+			summary.TotalLength, summary.Generated, summary.TimeMs, summary.AvgLength, summary.AvgTimeMs)
+		return err
+	}
+	return writeRow, writeSummary
+}
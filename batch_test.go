@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"fifteen/puzzle"
+)
+
+// batchInput has a comment, an empty line, two solvable puzzles, an unsolvable one and a
+// line that cannot be read.
+const batchInput = `# comment
+5 1 2 4 9 6 3 8 13 10 7 11 14 0 15 12
+
+2 1 3 4 5 6 7 8 9 10 11 12 13 14 15 0
+1 2 3
+1 2 3 4 5 6 7 8 9 10 11 12 13 14 0 15
+`
+
+// setCLIOptions configures the CLI globals for a test and restores them when it ends.
+func setCLIOptions(t *testing.T, opts puzzle.Options) {
+	t.Helper()
+	savedOptions, savedQuiet, savedNotation := options, quiet, notation
+	savedRows, savedCols := boardRows, boardCols
+	t.Cleanup(func() {
+		options, quiet, notation = savedOptions, savedQuiet, savedNotation
+		boardRows, boardCols = savedRows, savedCols
+	})
+	if opts.Metric == "" {
+		opts.Metric = puzzle.MetricSingle
+	}
+	opts.Tables = newTables(true)
+	options, notation, boardRows, boardCols = opts, puzzle.NotationBlank, 4, 4
+}
+
+func TestBatchRows(t *testing.T) {
+	want := []batchRow{
+		{Line: 2, Puzzle: "5 1 2 4 9 6 3 8 13 10 7 11 14 0 15 12", Solvable: true, Solved: true, Length: 10, Quality: "proven optimal"},
+		{Line: 4, Puzzle: "2 1 3 4 5 6 7 8 9 10 11 12 13 14 15 0"},
+		{Line: 5, Puzzle: "1 2 3"},
+		{Line: 6, Puzzle: "1 2 3 4 5 6 7 8 9 10 11 12 13 14 0 15", Solvable: true, Solved: true, Length: 1, Moves: "R", Quality: "proven optimal"},
+	}
+	const totals = "# puzzles=4 solvable=2 unsolvable=1 solved=2 errors=1\n# total_length=11 "
+
+	check := func(t *testing.T, got []batchRow) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("%d rows, want %d: %+v", len(got), len(want), got)
+		}
+		for i, row := range got {
+			w := want[i]
+			if row.Line != w.Line || row.Puzzle != w.Puzzle || row.Solvable != w.Solvable || row.Solved != w.Solved ||
+				row.Length != w.Length || row.Quality != w.Quality || (w.Moves != "" && row.Moves != w.Moves) {
+				t.Errorf("row %d = %+v, want %+v", i, row, w)
+			}
+			if row.Solved && len(row.Moves) != row.Length {
+				t.Errorf("row %d: moves %q for length %d", i, row.Moves, row.Length)
+			}
+			if (row.Error != "") != (w.Line == 5) {
+				t.Errorf("row %d: error %q", i, row.Error)
+			}
+		}
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		setCLIOptions(t, puzzle.Options{Heuristic: puzzle.HeuristicAdmissible})
+		var summary batchSummary
+		var err error
+		out := captureOutput(t, func() {
+			summary, err = runBatchReader(context.Background(), strings.NewReader(batchInput), "test", batchCSV)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code := summary.exitCode(); code != exitError {
+			t.Errorf("exit code %d, want %d", code, exitError)
+		}
+		reader := csv.NewReader(strings.NewReader(out))
+		reader.Comment = '#'
+		records, err := reader.ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) == 0 || strings.Join(records[0], ",") != "line,puzzle,solvable,solved,length,moves,generated,expanded,time_ms,quality,error" {
+			t.Fatalf("header %v", records)
+		}
+		var rows []batchRow
+		for _, record := range records[1:] {
+			rows = append(rows, batchRow{
+				Line:     atoi(t, record[0]),
+				Puzzle:   record[1],
+				Solvable: record[2] == "true",
+				Solved:   record[3] == "true",
+				Length:   atoi(t, record[4]),
+				Moves:    record[5],
+				Quality:  record[9],
+				Error:    record[10],
+			})
+		}
+		check(t, rows)
+		if !strings.Contains(out, totals) {
+			t.Errorf("output does not contain the totals %q:\n%s", totals, out)
+		}
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		setCLIOptions(t, puzzle.Options{Heuristic: puzzle.HeuristicAdmissible})
+		var err error
+		out := captureOutput(t, func() {
+			_, err = runBatchReader(context.Background(), strings.NewReader(batchInput), "test", batchJSONL)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rows []batchRow
+		var last map[string]batchSummary
+		scanner := bufio.NewScanner(strings.NewReader(out))
+		for scanner.Scan() {
+			if strings.HasPrefix(scanner.Text(), `{"summary"`) {
+				if err := json.Unmarshal(scanner.Bytes(), &last); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+			var row batchRow
+			if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+				t.Fatalf("%q: %v", scanner.Text(), err)
+			}
+			rows = append(rows, row)
+		}
+		check(t, rows)
+		summary, ok := last["summary"]
+		if !ok {
+			t.Fatalf("no summary line:\n%s", out)
+		}
+		if summary.Puzzles != 4 || summary.Solvable != 2 || summary.Unsolvable != 1 || summary.Solved != 2 ||
+			summary.Errors != 1 || summary.TotalLength != 11 || summary.AvgLength != 5.5 {
+			t.Errorf("summary %+v", summary)
+		}
+	})
+}
+
+func TestBatchExitCode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  puzzle.Options
+		want  int
+	}{
+		{"solved", "1 2 3 4 5 6 7 8 9 10 11 12 13 14 0 15\n", puzzle.Options{}, exitOK},
+		{"unsolvable", "2 1 3 4 5 6 7 8 9 10 11 12 13 14 15 0\n", puzzle.Options{}, exitUnsolvable},
+		{"not solved", "5 1 2 4 9 6 3 8 13 10 7 11 14 0 15 12\n", puzzle.Options{MaxNodes: 5}, exitNotSolved},
+		{"unreadable", "1 2 3\n2 1 3 4 5 6 7 8 9 10 11 12 13 14 15 0\n", puzzle.Options{}, exitError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setCLIOptions(t, test.opts)
+			var summary batchSummary
+			var err error
+			captureOutput(t, func() {
+				summary, err = runBatchReader(context.Background(), strings.NewReader(test.input), "test", batchCSV)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if code := summary.exitCode(); code != test.want {
+				t.Errorf("exit code %d, want %d", code, test.want)
+			}
+		})
+	}
+}
+
+// atoi converts a number of the CSV output or fails the test.
+func atoi(t *testing.T, text string) int {
+	t.Helper()
+	n, err := strconv.Atoi(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
@@ -0,0 +1,857 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"fifteen/puzzle"
+)
+
+// Exit codes of the solver binary.
+const (
+	exitOK         = 0
+	exitError      = 1 // invalid input, missing tables or I/O errors
+	exitUsage      = 2 // unknown subcommand or invalid flags
+	exitUnsolvable = 3 // the puzzle has no solution
+	exitNotSolved  = 4 // the search stopped before finding a solution
+)
+
+// subcommands lists the subcommands with their one-line description, in help order.
+var subcommands = []struct {
+	name, description string
+	run               func(args []string) int
+}{
+	{"solve", "resuelve un puzzle (por defecto si no se indica subcomando)", runSolve},
+	{"gen-tables", "genera las tablas de walking distance y de bases de patrones", runGenTables},
+	{"convert-table", "convierte la tabla de walking distance entre JSON y el formato binario", runConvertTable},
+	{"verify", "verifica una solución contra las reglas de movimiento", runVerifyCommand},
+	{"random", "genera puzzles aleatorios resolubles", runRandom},
+	{"bench", "resuelve un conjunto fijo de puzzles y muestra tiempos y estados", runBench},
+	{"explain", "muestra la resolubilidad y los componentes de la heurística de un puzzle", runExplain},
+}
+
+// runCLI dispatches the subcommand and returns the exit code. Without a subcommand (or
+// when the first argument is a flag) it runs solve, so "./solver -extra_heuristic" still works.
+func runCLI(args []string) int {
+	if len(args) == 0 || (strings.HasPrefix(args[0], "-") && !isHelpFlag(args[0])) {
+		return runSolve(args)
+	}
+	if isHelpFlag(args[0]) || args[0] == "help" {
+		printUsage(os.Stdout)
+		return exitOK
+	}
+	for _, command := range subcommands {
+		if command.name == args[0] {
+			return command.run(args[1:])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Subcomando desconocido: %s\n\n", args[0])
+	printUsage(os.Stderr)
+	return exitUsage
+}
+
+// isHelpFlag reports whether arg asks for help.
+func isHelpFlag(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+// printUsage muestra los subcomandos disponibles.
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Uso: solver <subcomando> [flags] [puzzle]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Subcomandos:")
+	for _, command := range subcommands {
+		fmt.Fprintf(w, "  %-13s %s\n", command.name, command.description)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Use \"solver <subcomando> -h\" para ver los flags de cada subcomando.")
+	fmt.Fprintln(w, "Códigos de salida: 0 ok, 1 error, 2 uso incorrecto, 3 puzzle sin solución, 4 búsqueda sin solución.")
+}
+
+// optionalFlag is a string flag that can also be given without a value ("-pdb" or
+// "-pdb=5-5-5"); without a value it takes bare.
+type optionalFlag struct {
+	target *string
+	bare   string
+}
+
+func (f optionalFlag) String() string {
+	if f.target == nil {
+		return ""
+	}
+	return *f.target
+}
+
+func (f optionalFlag) Set(value string) error {
+	if value == "true" {
+		value = f.bare
+	} else if value == "false" {
+		value = ""
+	}
+	*f.target = value
+	return nil
+}
+
+// IsBoolFlag lets the flag package accept the flag without a value.
+func (f optionalFlag) IsBoolFlag() bool { return true }
+
+// solverFlags holds the raw values of the flags shared by solve, bench and explain
+// before they are validated and copied into options.
+type solverFlags struct {
+	heuristic  string
+	extra      bool
+	optimal    bool
+	pdb        string
+	parallel   string
+	tt         string
+	formatFlag string
+	noGenerate bool
+	progress   string
+	size       string
+	goal       string
+	metric     string
+	blocked    string
+	walls      string
+}
+
+// newFlagSet creates a flag set that reports errors instead of exiting, with a usage
+// message that shows the subcommand syntax.
+func newFlagSet(name, syntax string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Uso: solver %s %s\n\nFlags:\n", name, syntax)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// addHeuristicFlags registers the flags that select the heuristic and the table directory.
+func addHeuristicFlags(fs *flag.FlagSet, raw *solverFlags) {
+	fs.StringVar(&raw.heuristic, "heuristic", puzzle.HeuristicDefault, "heurística: default, extra (con corner conflict), admissible o pdb")
+	fs.BoolVar(&raw.extra, "extra_heuristic", false, "equivale a -heuristic=extra")
+	fs.BoolVar(&raw.optimal, "optimal", false, "equivale a -heuristic=admissible")
+	fs.Var(optionalFlag{&raw.pdb, puzzle.DefaultPartition}, "pdb", "usa bases de patrones con la partición dada (6-6-3 o 5-5-5; sin valor 6-6-3)")
+	fs.StringVar(&tablesDir, "tables", tablesDir, "directorio de las tablas de heurísticas (por defecto $"+tablesEnv+" o el directorio actual)")
+	fs.BoolVar(&raw.noGenerate, "no-generate", false, "falla si falta una tabla en lugar de generarla")
+	fs.StringVar(&raw.size, "size", "4x4", "tamaño del tablero, filas x columnas (por ejemplo 3x3 o 3x5); fuera de 4x4 la heurística es max(Walking Distance, Manhattan + Linear Conflict)")
+	fs.StringVar(&raw.goal, "goal", puzzle.GoalStandard, "objetivo: standard, blank-first, snake, spiral o la lista de fichas (por ejemplo \"0 1 2 ... 15\")")
+	fs.StringVar(&raw.metric, "metric", puzzle.MetricSingle, "métrica de movimientos: single (una ficha por movimiento) o multi (varias fichas de una fila o columna)")
+	fs.StringVar(&raw.blocked, "blocked", "", "casillas bloqueadas, numeradas desde 0 fila por fila (por ejemplo \"5,10\"); conservan la ficha del objetivo")
+	fs.StringVar(&raw.walls, "walls", "", "paredes entre casillas vecinas (por ejemplo \"1-2,5-9\")")
+}
+
+// addSolverFlags registers the flags of the search algorithms and of the output.
+func addSolverFlags(fs *flag.FlagSet, raw *solverFlags) {
+	addHeuristicFlags(fs, raw)
+	fs.StringVar(&options.Algorithm, "algo", "idastar", "algoritmo: idastar, astar, wastar, bfs, rbfs o anytime")
+	fs.Float64Var(&options.Weight, "weight", 2, "peso de la heurística en wastar y anytime (>= 1)")
+	fs.IntVar(&options.MaxStates, "max-states", puzzle.DefaultMaxStates, "máximo de estados en memoria para astar, wastar, bfs y anytime, y para decidir si un puzzle con -blocked o -walls tiene solución")
+	fs.Var(optionalFlag{&raw.parallel, strconv.Itoa(runtime.NumCPU())}, "parallel", "IDA* paralelo con N goroutines (sin valor, todos los núcleos)")
+	fs.Var(optionalFlag{&raw.tt, strconv.Itoa(puzzle.DefaultTTMegabytes)}, "tt", "tabla de transposición de N MB para IDA* (sin valor, 64)")
+	fs.StringVar(&options.TTPolicy, "tt-policy", "shallow", "política de reemplazo de la tabla de transposición: shallow o always")
+	fs.DurationVar(&options.Timeout, "timeout", 0, "detiene la búsqueda después de este tiempo y muestra el mejor camino parcial (por ejemplo 30s)")
+	fs.IntVar(&options.MaxNodes, "max-nodes", 0, "detiene la búsqueda después de generar N estados (0 sin límite)")
+	fs.DurationVar(&options.TimeLimit, "time-limit", 0, "tiempo disponible para el solver anytime (por ejemplo 500ms o 10s)")
+	fs.StringVar(&notation, "notation", notation, "notación de los movimientos: blank o tile")
+	fs.StringVar(&raw.formatFlag, "format", formatText, "formato de salida: text o json")
+	fs.BoolVar(&verbose, "verbose", verbose, "muestra el tablero de cada paso de la solución")
+	fs.BoolVar(&quiet, "quiet", quiet, "no muestra mensajes de progreso")
+	fs.StringVar(&raw.progress, "progress", progressAuto, "progreso de cada iteración de IDA*: text, json, none o auto (text salvo con -quiet, -format json o batch)")
+}
+
+// applyHeuristicFlags validates the heuristic flags and configures the puzzle package with them.
+func applyHeuristicFlags(raw solverFlags) error {
+	if err := setHeuristicOptions(raw); err != nil {
+		return err
+	}
+	return configure()
+}
+
+// setHeuristicOptions copies the heuristic flags and -size into options.
+func setHeuristicOptions(raw solverFlags) error {
+	heuristic := raw.heuristic
+	if raw.extra {
+		heuristic = puzzle.HeuristicExtra
+	}
+	if raw.optimal {
+		heuristic = puzzle.HeuristicAdmissible
+	}
+	if raw.pdb != "" {
+		heuristic = puzzle.HeuristicPDB
+	}
+
+	rows, cols, err := puzzle.ParseSize(raw.size)
+	if err != nil {
+		return err
+	}
+	boardRows, boardCols = rows, cols
+	goal, err := puzzle.GoalLayout(raw.goal, rows, cols)
+	if err != nil {
+		return err
+	}
+	options.Heuristic, options.Partition, options.Goal = heuristic, raw.pdb, goal
+	options.Metric = raw.metric
+	if options.Obstacles, err = puzzle.ParseObstacles(raw.blocked, raw.walls, rows, cols); err != nil {
+		return err
+	}
+	if heuristic == puzzle.HeuristicPDB && options.Partition == "" {
+		options.Partition = puzzle.DefaultPartition
+	}
+	options.Tables = newTables(raw.noGenerate)
+	return nil
+}
+
+// applySolverFlags validates every shared flag and configures the puzzle package with them.
+func applySolverFlags(raw solverFlags) error {
+	options.Workers, options.TTMegabytes = 0, 0
+	if raw.parallel != "" {
+		n, err := strconv.Atoi(raw.parallel)
+		if err != nil || n < 1 {
+			return fmt.Errorf("número de workers inválido: %s", raw.parallel)
+		}
+		options.Workers = n
+	}
+	if raw.tt != "" {
+		n, err := strconv.Atoi(raw.tt)
+		if err != nil || n < 1 {
+			return fmt.Errorf("tamaño de tabla de transposición inválido: %s", raw.tt)
+		}
+		options.TTMegabytes = n
+	}
+	if err := setHeuristicOptions(raw); err != nil {
+		return err
+	}
+	if !boardIs4x4() && (options.Algorithm != "idastar" || raw.parallel != "" || raw.tt != "") {
+		return fmt.Errorf("los tableros %dx%d solo se resuelven con IDA* secuencial (sin -algo, -parallel ni -tt)", boardRows, boardCols)
+	}
+	if notation != puzzle.NotationBlank && notation != puzzle.NotationTile {
+		return fmt.Errorf("notación desconocida: %s (opciones: blank, tile)", notation)
+	}
+	outputFormat = raw.formatFlag
+	if outputFormat != formatText && outputFormat != formatJSON {
+		return fmt.Errorf("formato desconocido: %s (opciones: text, json)", outputFormat)
+	}
+	switch raw.progress {
+	case progressAuto, progressText, progressJSON, progressNone:
+	default:
+		return fmt.Errorf("progreso desconocido: %s (opciones: text, json, none, auto)", raw.progress)
+	}
+	return configure()
+}
+
+// configure checks options for the -size boards and makes them the configuration of the
+// puzzle package. Messages go to stdout unless quiet is set.
+func configure() error {
+	options.Log = nil
+	if !quiet {
+		options.Log = os.Stdout
+	}
+	if err := options.Supports(boardRows, boardCols); err != nil {
+		return err
+	}
+	return puzzle.Configure(options)
+}
+
+// setProgress installs the progress renderer of -progress. Progress goes to stdout only
+// when the output is the text report; otherwise it goes to stderr so that stdout stays
+// machine readable. auto shows text progress only for interactive text runs.
+func setProgress(mode string, interactive bool) {
+	if mode == progressAuto {
+		mode = progressNone
+		if interactive {
+			mode = progressText
+		}
+	}
+	var w io.Writer = os.Stderr
+	if interactive {
+		w = os.Stdout
+	}
+	options.Progress = progressRenderer(mode, w)
+}
+
+// parseFlags parses args and converts the result into an exit code when parsing fails or
+// help was requested; ok is false in that case.
+func parseFlags(fs *flag.FlagSet, args []string) (code int, ok bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+// readPuzzle returns the puzzle of the -size board given as positional arguments (one
+// argument with all the numbers or one argument per number) or, if there are none, the
+// first line of stdin.
+func readPuzzle(args []string, prompt bool) (puzzle.Board, error) {
+	if len(args) > 0 {
+		return puzzle.ParseBoard(strings.Join(args, " "), boardRows, boardCols)
+	}
+	if prompt {
+		fmt.Printf("Ingrese %d números separados por espacio:\n", boardRows*boardCols)
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Scan()
+	return puzzle.ParseBoard(scanner.Text(), boardRows, boardCols)
+}
+
+// boardIs4x4 reports whether -size selects the 4x4 board.
+func boardIs4x4() bool {
+	return boardRows == 4 && boardCols == 4
+}
+
+// prepareTables configures the puzzle package with options and prepares the tables of the
+// -size boards (see puzzle.PrepareTables). A missing table that cannot be generated gets a
+// hint on how to provide it.
+func prepareTables() error {
+	if err := configure(); err != nil {
+		return err
+	}
+	err := puzzle.PrepareTables(boardRows, boardCols)
+	if errors.Is(err, puzzle.ErrMissingTable) {
+		command := "solver gen-tables"
+		if !boardIs4x4() {
+			command += fmt.Sprintf(" -size %dx%d", boardRows, boardCols)
+		}
+		if options.Heuristic == puzzle.HeuristicPDB {
+			command += " -pdb=" + options.Partition
+		}
+		if options.Metric == puzzle.MetricMulti {
+			command += " -metric=" + puzzle.MetricMulti
+		}
+		return fmt.Errorf("%w (use -tables=DIR o la variable %s, o genérela con: %s)", err, tablesEnv, command)
+	}
+	return err
+}
+
+// runSolve implementa el subcomando solve.
+func runSolve(args []string) int {
+	var raw solverFlags
+	fs := newFlagSet("solve", "[flags] [16 números]")
+	addSolverFlags(fs, &raw)
+	fs.StringVar(&batchFile, "batch", "", "resuelve un puzzle por línea del archivo (- para stdin)")
+	fs.StringVar(&batchFormat, "batch-format", batchCSV, "formato del modo batch: csv o jsonl")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if err := applySolverFlags(raw); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	setProgress(raw.progress, !quiet && outputFormat == formatText && batchFile == "")
+
+	// Ctrl-C detiene la búsqueda en curso, que igual informa sus estadísticas.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if batchFile != "" {
+		if batchFormat != batchCSV && batchFormat != batchJSONL {
+			fmt.Fprintln(os.Stderr, "Formato batch desconocido:", batchFormat, "(opciones: csv, jsonl)")
+			return exitUsage
+		}
+		summary, err := runBatch(ctx, batchFile, batchFormat)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error en el modo batch:", err)
+			return exitError
+		}
+		return summary.exitCode()
+	}
+
+	if outputFormat == formatJSON {
+		report, err := runJSON(ctx, fs.Args())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error escribiendo el JSON:", err)
+			return exitError
+		}
+		return reportExitCode(report)
+	}
+
+	initial, err := readPuzzle(fs.Args(), !quiet)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	if err := prepareTables(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	if err := initial.CheckObstacles(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	if options.Metric == puzzle.MetricMulti {
+		report := initial.HeuristicReport()
+		fmt.Printf("Heuristica usada: %s (métrica multi), Total: %d\n", report.Formula, report.Total)
+	} else if options.Obstacles != nil {
+		report := initial.HeuristicReport()
+		fmt.Printf("Heuristica usada: %s (casillas bloqueadas y paredes), Total: %d\n", report.Formula, report.Total)
+	} else if !boardIs4x4() {
+		if report := initial.HeuristicReport(); report.WalkingDistance != nil {
+			fmt.Printf("Heuristica usada: max(Walking Distance, Manhattan + Linear Conflict), Total: %d\n", report.Total)
+		} else {
+			fmt.Printf("Heuristica usada: Manhattan + Linear Conflict, Total: %d\n", report.Total)
+		}
+	} else if options.Heuristic == puzzle.HeuristicPDB {
+		value, err := puzzle.PatternDatabaseHeuristic(initial.State())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		fmt.Printf("Heuristica usada: bases de patrones aditivas %s, Total: %d\n", options.Partition, value)
+	} else {
+		printHeuristicFormula(initial.HeuristicReport(), options.Heuristic == puzzle.HeuristicAdmissible)
+	}
+
+	// Display puzzle state
+	fmt.Println("\nCurrent puzzle state:")
+	for _, row := range initial.Matrix() {
+		for _, val := range row {
+			fmt.Printf("%d\t", val)
+		}
+		fmt.Println()
+	}
+
+	// -timeout cubre también la comprobación de resolubilidad, que con -walls puede buscar.
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+	// Si no se pudo decidir (o se agotó el tiempo) se intenta resolverlo igual: Solve
+	// informa por qué se detuvo.
+	switch solvable, err := initial.IsSolvable(ctx); {
+	case err != nil:
+		fmt.Println("The puzzle solvability is unknown.")
+	case solvable:
+		fmt.Println("The puzzle is solvable.")
+	default:
+		fmt.Println("The puzzle is not solvable.")
+		return exitUnsolvable
+	}
+
+	result, err := puzzle.Solve(ctx, initial, options)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	printSolveResult(result)
+	if !result.Solved {
+		return exitNotSolved
+	}
+	return exitOK
+}
+
+// reportExitCode returns the exit code that matches a JSON report.
+func reportExitCode(report solveReport) int {
+	switch {
+	case report.Error != "":
+		return exitError
+	case !report.Solvable:
+		return exitUnsolvable
+	case !report.Solved:
+		return exitNotSolved
+	}
+	return exitOK
+}
+
+// runGenTables implementa el subcomando gen-tables.
+func runGenTables(args []string) int {
+	fs := newFlagSet("gen-tables", "[flags]")
+	fs.StringVar(&tablesDir, "tables", tablesDir, "directorio donde se guardan las tablas")
+	partition := fs.String("pdb", "", "genera también las bases de patrones de la partición (6-6-3, 5-5-5 o all)")
+	layout := fs.String("goal", puzzle.GoalStandard, "objetivo de las tablas (como en solve)")
+	size := fs.String("size", "4x4", "tamaño del tablero de las tablas de walking distance (como en solve)")
+	metric := fs.String("metric", puzzle.MetricSingle, "métrica de las tablas de walking distance (como en solve)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	rows, cols, err := puzzle.ParseSize(*size)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if *partition != "" && (rows != 4 || cols != 4 || *metric == puzzle.MetricMulti) {
+		fmt.Fprintln(os.Stderr, "Las bases de patrones solo están disponibles para tableros 4x4 en la métrica single")
+		return exitUsage
+	}
+	goal, err := puzzle.GoalLayout(*layout, rows, cols)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	var partitions []string
+	switch {
+	case *partition == "all":
+		partitions = puzzle.Partitions()
+	case *partition != "":
+		if !knownPartition(*partition) {
+			fmt.Fprintln(os.Stderr, "Partición desconocida:", *partition, "(opciones: 6-6-3, 5-5-5, all)")
+			return exitUsage
+		}
+		partitions = append(partitions, *partition)
+	}
+	if err := puzzle.Configure(puzzle.Options{Goal: goal, Metric: *metric, Tables: newTables(false), Log: os.Stdout}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	// Las tablas de walking distance del tamaño y las bases de patrones de cada partición.
+	if err := puzzle.PrepareTables(rows, cols); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	for _, name := range partitions {
+		if err := puzzle.GeneratePatternDatabases(name); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+	}
+	return exitOK
+}
+
+// knownPartition reports whether name is one of puzzle.Partitions.
+func knownPartition(name string) bool {
+	for _, partition := range puzzle.Partitions() {
+		if partition == name {
+			return true
+		}
+	}
+	return false
+}
+
+// runConvertTable implementa el subcomando convert-table.
+func runConvertTable(args []string) int {
+	fs := newFlagSet("convert-table", "ENTRADA SALIDA  (ENTRADA .json se convierte a binario, otra extensión a JSON; una base de patrones sin cabecera se convierte al formato binario)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return exitUsage
+	}
+	if err := puzzle.ConvertTable(fs.Arg(0), fs.Arg(1)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	return exitOK
+}
+
+// runVerifyCommand implementa el subcomando verify.
+func runVerifyCommand(args []string) int {
+	fs := newFlagSet("verify", `[flags] "<números del tablero>" <movimientos>`)
+	moveNotation := fs.String("notation", puzzle.NotationBlank, "notación de los movimientos: blank o tile")
+	statesFile := fs.String("states", "", "archivo con un estado por línea en lugar de movimientos")
+	size := fs.String("size", "4x4", "tamaño del tablero, filas x columnas (como en solve)")
+	layout := fs.String("goal", puzzle.GoalStandard, "objetivo que debe alcanzar la solución (como en solve)")
+	metric := fs.String("metric", puzzle.MetricSingle, "métrica en la que se cuentan los movimientos (como en solve)")
+	blocked := fs.String("blocked", "", "casillas bloqueadas (como en solve)")
+	walls := fs.String("walls", "", "paredes entre casillas vecinas (como en solve)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	rows, cols, err := puzzle.ParseSize(*size)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	goal, err := puzzle.GoalLayout(*layout, rows, cols)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	obstacles, err := puzzle.ParseObstacles(*blocked, *walls, rows, cols)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if err := puzzle.Configure(puzzle.Options{Goal: goal, Metric: *metric, Obstacles: obstacles}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if *moveNotation != puzzle.NotationBlank && *moveNotation != puzzle.NotationTile {
+		fmt.Fprintln(os.Stderr, "Notación desconocida:", *moveNotation, "(opciones: blank, tile)")
+		return exitUsage
+	}
+
+	var report puzzle.VerifyReport
+	if *statesFile != "" {
+		boards, err := puzzle.ReadBoards(*statesFile, rows, cols)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		report = puzzle.VerifyBoardStates(boards)
+	} else {
+		if fs.NArg() != 2 {
+			fs.Usage()
+			return exitUsage
+		}
+		start, err := puzzle.ParseBoard(fs.Arg(0), rows, cols)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		moves, err := puzzle.ParseMoves(fs.Arg(1), *moveNotation)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		report = puzzle.VerifyBoardMoves(start, moves)
+	}
+
+	printVerifyReport(report, *metric)
+	if !report.Valid || !report.ReachesGoal {
+		return exitError
+	}
+	return exitOK
+}
+
+// runRandom implementa el subcomando random: escribe puzzles resolubles, uno por línea,
+// precedidos por un comentario con la semilla para poder repetirlos.
+func runRandom(args []string) int {
+	var raw solverFlags
+	var opts puzzle.RandomOptions
+	fs := newFlagSet("random", "[flags]")
+	addHeuristicFlags(fs, &raw)
+	count := fs.Int("n", 1, "cantidad de puzzles")
+	seed := fs.Int64("seed", 0, "semilla del generador (0 usa la hora actual)")
+	fs.StringVar(&opts.Method, "method", puzzle.RandomUniform, "uniform (permutación resoluble al azar) o walk (camino al azar desde el objetivo)")
+	fs.IntVar(&opts.WalkLength, "length", 40, "cantidad de movimientos de cada camino con -method=walk")
+	fs.IntVar(&opts.MinH, "min-h", 0, "heurística mínima")
+	fs.IntVar(&opts.MaxH, "max-h", 0, "heurística máxima (0 sin límite)")
+	fs.IntVar(&opts.MinDistance, "min-dist", 0, "distancia óptima mínima, verificada con IDA* (necesita -heuristic=admissible o pdb)")
+	fs.IntVar(&opts.MaxDistance, "max-dist", 0, "distancia óptima máxima, verificada con IDA* (0 sin límite)")
+	fs.IntVar(&opts.Attempts, "attempts", puzzle.DefaultRandomAttempts, "intentos por puzzle antes de rendirse")
+	fs.StringVar(&raw.formatFlag, "format", formatText, "formato de salida: text (una línea por puzzle) o json (un objeto por línea)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if err := applyHeuristicFlags(raw); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if raw.formatFlag != formatText && raw.formatFlag != formatJSON {
+		fmt.Fprintln(os.Stderr, "Formato desconocido:", raw.formatFlag, "(opciones: text, json)")
+		return exitUsage
+	}
+	if *count < 0 || opts.WalkLength < 0 || opts.Attempts < 1 {
+		fmt.Fprintln(os.Stderr, "Los valores de -n, -length y -attempts deben ser positivos")
+		return exitUsage
+	}
+	opts.Rows, opts.Cols = boardRows, boardCols
+	if opts.VerifiesDistance() && boardIs4x4() && !options.Admissible() {
+		fmt.Fprintln(os.Stderr, "El filtro de distancia necesita una heurística admisible (-heuristic=admissible o pdb)")
+		return exitUsage
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
+	quiet = true
+	if err := prepareTables(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if raw.formatFlag == formatText {
+		fmt.Printf("# seed=%d method=%s\n", *seed, opts.Method)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	puzzles, err := puzzle.GenerateRandom(ctx, rand.New(rand.NewSource(*seed)), opts, *count)
+	for _, p := range puzzles {
+		if raw.formatFlag != formatJSON {
+			fmt.Println(p.State)
+			continue
+		}
+		if err := encoder.Encode(p); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	return exitOK
+}
+
+// benchPuzzles are the instances solved by the bench subcommand, with optimal solutions
+// between 10 and 56 moves.
+var benchPuzzles = []string{
+	"5 1 2 4 9 6 3 8 13 10 7 11 14 0 15 12",
+	"3 1 11 7 4 15 5 10 8 6 2 13 14 0 9 12",
+	"2 15 4 12 9 5 10 14 1 6 0 7 8 3 13 11",
+	"1 11 2 10 13 7 15 6 12 8 4 9 14 0 3 5",
+	"1 8 0 6 12 9 4 7 15 13 14 11 2 3 5 10",
+	"9 12 11 13 4 1 8 5 10 14 0 7 3 2 15 6",
+	"1 6 14 13 3 15 8 4 7 9 0 2 12 11 5 10",
+	"4 6 12 14 15 8 2 0 10 3 5 13 7 1 9 11",
+}
+
+// runBench implementa el subcomando bench: resuelve benchPuzzles con el algoritmo y la
+// heurística elegidos y escribe una fila por puzzle y los totales, como el modo batch.
+func runBench(args []string) int {
+	var raw solverFlags
+	fs := newFlagSet("bench", "[flags]")
+	addSolverFlags(fs, &raw)
+	format := fs.String("bench-format", batchCSV, "formato de los resultados: csv o jsonl")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if err := applySolverFlags(raw); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if *format != batchCSV && *format != batchJSONL {
+		fmt.Fprintln(os.Stderr, "Formato desconocido:", *format, "(opciones: csv, jsonl)")
+		return exitUsage
+	}
+	setProgress(raw.progress, false)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if _, err := runBatchReader(ctx, strings.NewReader(strings.Join(benchPuzzles, "\n")), "bench", *format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	return exitOK
+}
+
+// explainReport is the JSON document written by explain.
+type explainReport struct {
+	Input      puzzle.Board           `json:"input"`
+	Goal       puzzle.Board           `json:"goal"`
+	Solvable   *bool                  `json:"solvable"` // null if it could not be decided
+	Inversions int                    `json:"inversions"`
+	BlankRow   int                    `json:"blank_row_from_bottom"`
+	Heuristic  puzzle.HeuristicReport `json:"heuristic"`
+}
+
+// runExplain implementa el subcomando explain.
+func runExplain(args []string) int {
+	var raw solverFlags
+	fs := newFlagSet("explain", "[flags] [16 números]")
+	addHeuristicFlags(fs, &raw)
+	fs.StringVar(&raw.formatFlag, "format", formatText, "formato de salida: text o json")
+	fs.IntVar(&options.MaxStates, "max-states", puzzle.DefaultMaxStates, "máximo de tableros para decidir si un puzzle con -blocked o -walls tiene solución")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if err := applyHeuristicFlags(raw); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if raw.formatFlag != formatText && raw.formatFlag != formatJSON {
+		fmt.Fprintln(os.Stderr, "Formato desconocido:", raw.formatFlag, "(opciones: text, json)")
+		return exitUsage
+	}
+
+	quiet = raw.formatFlag == formatJSON
+	initial, err := readPuzzle(fs.Args(), !quiet)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	if err := prepareTables(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report := explainReport{
+		Input:      initial,
+		Goal:       initial.Goal().Board,
+		Inversions: initial.Inversions(),
+		BlankRow:   initial.BlankRowFromBottom(),
+		Heuristic:  initial.HeuristicReport(),
+	}
+	if solvable, err := initial.IsSolvable(ctx); err == nil {
+		report.Solvable = &solvable
+	}
+
+	if raw.formatFlag == formatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return exitError
+		}
+	} else {
+		printBoard(initial)
+		if !initial.Goal().IsStandard() {
+			fmt.Println("Objetivo:")
+			printBoard(report.Goal)
+		}
+		fmt.Printf("Inversiones: %d, fila del espacio vacío (desde abajo): %d\n", report.Inversions, report.BlankRow)
+		switch {
+		case report.Solvable == nil:
+			fmt.Println("The puzzle solvability is unknown.")
+		case *report.Solvable:
+			fmt.Println("The puzzle is solvable.")
+		default:
+			fmt.Println("The puzzle is not solvable.")
+		}
+		fmt.Println("Heurística:", report.Heuristic.Formula)
+		printHeuristicComponents(report.Heuristic)
+	}
+	switch {
+	case report.Solvable == nil:
+		return exitNotSolved
+	case !*report.Solvable:
+		return exitUnsolvable
+	}
+	return exitOK
+}
+
+// printHeuristicFormula muestra la fórmula y los valores de la heurística por defecto,
+// extra o admisible de un puzzle 4x4.
+func printHeuristicFormula(report puzzle.HeuristicReport, admissible bool) {
+	label := "Heuristica usada"
+	if admissible {
+		label += " (admisible)"
+	}
+	fmt.Printf("%s: h = %s\n", label, report.Formula)
+	fmt.Printf("Manhattan: %d, Linear Conflict: %d, Walking Distance: %d",
+		*report.Manhattan, *report.LinearConflict, *report.WalkingDistance)
+	if report.CornerConflict != nil {
+		fmt.Printf(", Corner Conflict: %d", *report.CornerConflict)
+	}
+	fmt.Printf(", Total: %d\n", report.Total)
+}
+
+// printHeuristicComponents muestra los componentes presentes en un puzzle.HeuristicReport.
+func printHeuristicComponents(report puzzle.HeuristicReport) {
+	components := []struct {
+		name  string
+		value *int
+	}{
+		{"Manhattan", report.Manhattan},
+		{"Linear Conflict", report.LinearConflict},
+		{"Walking Distance", report.WalkingDistance},
+		{"True Distance", report.TrueDistance},
+		{"Corner Conflict", report.CornerConflict},
+		{"Pattern Database", report.PatternDatabase},
+	}
+	for _, component := range components {
+		if component.value != nil {
+			fmt.Printf("  %s: %d\n", component.name, *component.value)
+		}
+	}
+	fmt.Printf("  Total: %d\n", report.Total)
+}
@@ -0,0 +1,8 @@
+//go:build !embedtables
+
+package main
+
+import "io/fs"
+
+// embeddedTables is nil unless the binary is built with the embedtables tag.
+var embeddedTables fs.FS
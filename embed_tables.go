@@ -0,0 +1,17 @@
+//go:build embedtables
+
+package main
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// embeddedFiles holds the walking distance table compiled into the binary, used when the
+// file is not found in tablesDir. Build with: go build -tags embedtables
+//
+//go:embed walking_distance.bin
+var embeddedFiles embed.FS
+
+// embeddedTables is embeddedFiles as the fallback of the tables.
+var embeddedTables fs.FS = embeddedFiles
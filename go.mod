@@ -0,0 +1,3 @@
+module fifteen
+
+go 1.18
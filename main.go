@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+
+	"fifteen/puzzle"
+)
+
+// options es la configuración del solver armada con los flags de solve, bench, random y explain.
+var options puzzle.Options
+
+// boardRows y boardCols son el tamaño de los tableros leídos (-size, 4x4 por defecto).
+var boardRows, boardCols = 4, 4
+
+// notation es la convención usada para escribir los movimientos (blank o tile).
+var notation = puzzle.NotationBlank
+
+// verbose muestra el tablero de cada paso de la solución además de los movimientos.
+var verbose = false
+
+// quiet suprime los mensajes de progreso de los solvers (se usa en el modo batch).
+var quiet = false
+
+// batchFile es el archivo con un puzzle por línea a resolver en modo batch ("-" para stdin).
+var batchFile = ""
+
+// batchFormat es el formato de los resultados del modo batch (csv o jsonl).
+var batchFormat = batchCSV
+
+// outputFormat es el formato de salida de un solo puzzle (text o json).
+var outputFormat = formatText
+
+// tablesEnv es la variable de entorno con el directorio de las tablas; -tables tiene prioridad.
+const tablesEnv = "SOLVER_TABLES"
+
+// tablesDir es el directorio donde se guardan y buscan las tablas de heurísticas.
+var tablesDir = defaultTablesDir()
+
+// defaultTablesDir devuelve el valor de SOLVER_TABLES o el directorio actual.
+func defaultTablesDir() string {
+	if dir := os.Getenv(tablesEnv); dir != "" {
+		return dir
+	}
+	return "."
+}
+
+// newTables devuelve las tablas de tablesDir, con la tabla embebida (si la hay) como respaldo.
+func newTables(noGenerate bool) *puzzle.Tables {
+	tables := puzzle.DirTables(tablesDir)
+	tables.NoGenerate = noGenerate
+	if embeddedTables != nil {
+		tables.Fallback = embeddedTables
+	}
+	return tables
+}
+
+func main() {
+	os.Exit(runCLI(os.Args[1:]))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+
+	"fifteen/puzzle"
+)
+
+// printSolveResult muestra la secuencia de estados y las estadísticas de una búsqueda
+// hecha con puzzle.Solve.
+func printSolveResult(result puzzle.Result) {
+	if !result.Solved {
+		fmt.Println("No se encontró solución.")
+		if result.Stopped != "" {
+			fmt.Println("Motivo:", result.Stopped)
+		}
+		if result.Bound > 0 {
+			fmt.Println("Último límite:", result.Bound)
+		}
+		fmt.Println("Estados generados:", result.Generated)
+		if len(result.BestBoardPath) > 0 {
+			moves, _ := puzzle.BoardMoves(result.BestBoardPath)
+			fmt.Printf("Mejor camino parcial (h = %d, notación %s): %s\n", result.BestH, notation, puzzle.FormatMoves(moves, notation))
+			if verbose {
+				printBoard(result.BestBoardPath[len(result.BestBoardPath)-1])
+			}
+		}
+		fmt.Println("Tiempo:", result.Elapsed)
+		return
+	}
+	fmt.Println("¡Solución encontrada!")
+	if verbose {
+		fmt.Println("Secuencia de estados:")
+		for i, board := range result.BoardPath {
+			fmt.Printf("Paso %d:\n", i)
+			printBoard(board)
+			fmt.Println()
+		}
+	}
+	fmt.Printf("Movimientos (notación %s): %s\n", notation, puzzle.FormatMetricMoves(result.Moves, notation, options.Metric))
+	fmt.Println("Algoritmo:", result.Algorithm)
+	fmt.Println("Número de movimientos:", result.Length)
+	fmt.Println("Calidad de la solución:", result.Quality)
+	if result.LowerBound > 0 && result.LowerBound < result.Length {
+		fmt.Printf("Cota inferior del óptimo: %d (como mucho %d movimientos de más)\n", result.LowerBound, result.Length-result.LowerBound)
+	}
+	if result.Stopped != "" {
+		fmt.Println("Búsqueda detenida:", result.Stopped)
+	}
+	fmt.Println("Estados generados:", result.Generated)
+	fmt.Println("Estados expandidos:", result.Expanded)
+	fmt.Println("Tiempo:", result.Elapsed)
+}
+
+// printBoard muestra un tablero de cualquier tamaño.
+func printBoard(b puzzle.Board) {
+	for row := 0; row < b.Rows; row++ {
+		for col := 0; col < b.Cols; col++ {
+			fmt.Printf("%2d ", b.Tiles[row*b.Cols+col])
+		}
+		fmt.Println()
+	}
+}
+
+// printVerifyReport muestra el resultado de la verificación. En la métrica multi la
+// longitud se muestra también en movimientos de una ficha.
+func printVerifyReport(report puzzle.VerifyReport, metric string) {
+	if report.Valid {
+		fmt.Println("Todos los movimientos son válidos.")
+	} else {
+		fmt.Println("Solución inválida:", report.Error)
+	}
+	if metric == puzzle.MetricMulti {
+		fmt.Printf("Longitud: %d (%d movimientos de una ficha)\n", report.Moves, report.Length)
+	} else {
+		fmt.Println("Longitud:", report.Moves)
+	}
+	fmt.Println("Estado final:")
+	printBoard(report.FinalBoard)
+	if report.ReachesGoal {
+		fmt.Println("El estado final es el objetivo.")
+	} else {
+		fmt.Println("El estado final NO es el objetivo.")
+	}
+}
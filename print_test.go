@@ -0,0 +1,107 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"fifteen/puzzle"
+)
+
+// captureOutput returns what f writes to os.Stdout.
+func captureOutput(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	f()
+	w.Close()
+	return <-done
+}
+
+// mustBoard parses a board or fails the test.
+func mustBoard(t *testing.T, text string, rows, cols int) puzzle.Board {
+	t.Helper()
+	b, err := puzzle.ParseBoard(text, rows, cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestPrintSolveResult(t *testing.T) {
+	start := mustBoard(t, "1 2 3 4 5 6 7 8 9 10 11 12 13 0 14 15", 4, 4)
+	middle := mustBoard(t, "1 2 3 4 5 6 7 8 9 10 11 12 13 14 0 15", 4, 4)
+	goal := mustBoard(t, "1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 0", 4, 4)
+	result := puzzle.Result{
+		Algorithm: "idastar",
+		Solved:    true,
+		Moves:     []puzzle.Move{puzzle.Right, puzzle.Right},
+		Length:    2,
+		Quality:   "proven optimal",
+		BoardPath: []puzzle.Board{start, middle, goal},
+	}
+	tests := []struct {
+		name     string
+		notation string
+		metric   string
+		verbose  bool
+		want     []string
+		notWant  []string
+	}{
+		{
+			name:     "blank notation",
+			notation: puzzle.NotationBlank,
+			want:     []string{"Movimientos (notación blank): RR\n", "Número de movimientos: 2\n"},
+			notWant:  []string{"Paso"},
+		},
+		{
+			name:     "tile notation",
+			notation: puzzle.NotationTile,
+			want:     []string{"Movimientos (notación tile): LL\n"},
+		},
+		{
+			name:     "multi metric",
+			notation: puzzle.NotationBlank,
+			metric:   puzzle.MetricMulti,
+			want:     []string{"Movimientos (notación blank): R2\n"},
+		},
+		{
+			name:     "verbose",
+			notation: puzzle.NotationBlank,
+			verbose:  true,
+			want: []string{
+				"Paso 0:\n 1  2  3  4 \n 5  6  7  8 \n 9 10 11 12 \n13  0 14 15 \n",
+				"Paso 1:\n 1  2  3  4 \n 5  6  7  8 \n 9 10 11 12 \n13 14  0 15 \n",
+				"Paso 2:\n 1  2  3  4 \n 5  6  7  8 \n 9 10 11 12 \n13 14 15  0 \n",
+			},
+		},
+	}
+	defer func(n string, v bool, m string) { notation, verbose, options.Metric = n, v, m }(notation, verbose, options.Metric)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			notation, verbose, options.Metric = test.notation, test.verbose, test.metric
+			out := captureOutput(t, func() { printSolveResult(result) })
+			for _, want := range test.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output does not contain %q:\n%s", want, out)
+				}
+			}
+			for _, notWant := range test.notWant {
+				if strings.Contains(out, notWant) {
+					t.Errorf("output contains %q:\n%s", notWant, out)
+				}
+			}
+		})
+	}
+}
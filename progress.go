@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"fifteen/puzzle"
+)
+
+// Progress output modes accepted by -progress.
+const (
+	progressAuto = "auto"
+	progressText = "text"
+	progressJSON = "json"
+	progressNone = "none"
+)
+
+// progressRenderer returns the ProgressFunc that writes the events to w in the given mode:
+// one line of text, one JSON object per line, or nothing.
+func progressRenderer(mode string, w io.Writer) puzzle.ProgressFunc {
+	switch mode {
+	case progressText:
+		return func(e puzzle.ProgressEvent) {
+			fmt.Fprintf(w, "Nuevo límite: %d Estados generados: %d (iteración: %d, %.0f estados/s, %v)\n",
+				e.NextBound, e.TotalNodes, e.Nodes, e.NodesPerSecond, e.Elapsed.Round(time.Millisecond))
+		}
+	case progressJSON:
+		encoder := json.NewEncoder(w)
+		return func(e puzzle.ProgressEvent) {
+			encoder.Encode(e)
+		}
+	}
+	return nil
+}
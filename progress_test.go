@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"fifteen/puzzle"
+)
+
+func TestProgressRenderer(t *testing.T) {
+	events := []puzzle.ProgressEvent{
+		{Bound: 30, NextBound: 32, Nodes: 1000, TotalNodes: 1500, Elapsed: 250 * time.Millisecond, ElapsedMs: 250, NodesPerSecond: 6000},
+		{Bound: 32, NextBound: 34, Nodes: 4000, TotalNodes: 5500, Elapsed: 1500 * time.Millisecond, ElapsedMs: 1500, NodesPerSecond: 3666.6},
+	}
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{progressText, "Nuevo límite: 32 Estados generados: 1500 (iteración: 1000, 6000 estados/s, 250ms)\n" +
+			"Nuevo límite: 34 Estados generados: 5500 (iteración: 4000, 3667 estados/s, 1.5s)\n"},
+		{progressJSON, `{"bound":30,"next_bound":32,"nodes":1000,"total_nodes":1500,"elapsed_ms":250,"nodes_per_sec":6000}` + "\n" +
+			`{"bound":32,"next_bound":34,"nodes":4000,"total_nodes":5500,"elapsed_ms":1500,"nodes_per_sec":3666.6}` + "\n"},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		render := progressRenderer(test.mode, &out)
+		if render == nil {
+			t.Fatalf("%s: no renderer", test.mode)
+		}
+		for _, e := range events {
+			render(e)
+		}
+		if out.String() != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.mode, out.String(), test.want)
+		}
+	}
+	if render := progressRenderer(progressNone, &bytes.Buffer{}); render != nil {
+		t.Error("none: got a renderer")
+	}
+}
+
+// TestProgressJSONLines checks that -progress=json writes one decodable event per line.
+func TestProgressJSONLines(t *testing.T) {
+	var out bytes.Buffer
+	setCLIOptions(t, puzzle.Options{Heuristic: puzzle.HeuristicAdmissible, Progress: progressRenderer(progressJSON, &out)})
+	quiet = true
+	if err := prepareTables(); err != nil {
+		t.Fatal(err)
+	}
+	initial := mustBoard(t, "5 4 8 3 14 9 6 11 13 1 0 12 2 10 15 7", 4, 4)
+	result, err := puzzle.Solve(context.Background(), initial, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	if len(lines) != len(result.Iterations) {
+		t.Fatalf("%d lines for %d iterations:\n%s", len(lines), len(result.Iterations), out.String())
+	}
+	for i, line := range lines {
+		var e puzzle.ProgressEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		if e.Bound != result.Iterations[i].Bound || e.TotalNodes != result.Iterations[i].Generated {
+			t.Errorf("line %d = %+v, iteration %+v", i, e, result.Iterations[i])
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package puzzle
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultMaxStates is the largest number of states A*, weighted A* and BFS keep in memory.
+const DefaultMaxStates = 5000000
+
+// Result is the result and statistics returned by every search algorithm.
+type Result struct {
+	Algorithm string
+	Solved    bool
+	// Path holds the states from the initial state to the goal, both included.
+	Path []State
+	// Moves holds the moves of the blank that follow Path, one per tile slid.
+	Moves []Move
+	// Length is the number of moves of the solution in the metric of the search
+	// (len(Moves) in the single-tile metric, see MoveCount).
+	Length    int
+	Generated int
+	Expanded  int
+	// Quality tells whether the length of the solution is proven optimal; it is empty
+	// when the search stopped without a solution.
+	Quality string
+	Elapsed time.Duration
+	// Stopped explains why the search ended without a solution, if it did.
+	Stopped string
+	// Iterations holds the bounds of each IDA* iteration (empty for the other algorithms).
+	Iterations []IterationInfo
+	// Bound is the last f bound searched by IDA* (0 for the other algorithms).
+	Bound int
+	// Improvements holds the successive solutions of anytime, the last one being Path.
+	Improvements []Improvement
+	// LowerBound is, when anytime ran out of time or nodes with an admissible heuristic,
+	// a lower bound of the optimal length: the solution has at most Length-LowerBound
+	// extra moves (0 when unknown).
+	LowerBound int
+	// BestPath leads to the state with the lowest heuristic seen when the search stops
+	// without a solution, and BestH is that heuristic.
+	BestPath []State
+	BestH    int
+	// BoardPath and BestBoardPath are Path and BestPath for boards of any size; they are
+	// filled by solveBoard.
+	BoardPath     []Board
+	BestBoardPath []Board
+}
+
+// setPartial fills BestPath and BestH from the best partial path of a search.
+func (r *Result) setPartial(best partialPath) {
+	if best.path != nil {
+		r.BestPath = unpackPath(best.path)
+		r.BestH = best.h
+	}
+}
+
+// solverAlgorithms maps the names accepted by Options.Algorithm to their implementation. Every
+// algorithm stops when the context ends or after generating maxNodes nodes.
+var solverAlgorithms = map[string]func(context.Context, State) Result{
+	"idastar": solverIDAStar,
+	"astar": func(ctx context.Context, initial State) Result {
+		return weightedAStar(ctx, initial, 1)
+	},
+	"wastar": func(ctx context.Context, initial State) Result {
+		return weightedAStar(ctx, initial, astarWeight)
+	},
+	"bfs":  breadthFirstSearch,
+	"rbfs": recursiveBestFirstSearch,
+	"anytime": func(ctx context.Context, initial State) Result {
+		return anytimeWeightedAStar(ctx, initial, astarWeight, timeLimit)
+	},
+}
+
+// heuristicAdmissible reports whether the selected heuristic never overestimates. The
+// multi-tile metric and the 4x4 boards with obstacles always use an admissible heuristic
+// (see boardSearcher.heuristic).
+func heuristicAdmissible() bool {
+	return optimalMode || pdbPartition != "" || moveMetric == MetricMulti || obstaclesFor(4, 4) != nil
+}
+
+// solveState runs the selected algorithm on a 4x4 state and measures how long it takes.
+// Boards rejected by ValidateState and heuristic tables that cannot be loaded are reported
+// in Stopped without searching. The search stops when ctx ends, when searchTimeout expires
+// or after maxNodes generated nodes, and the result then holds the reason, the statistics
+// so far and the best partial path.
+func solveState(ctx context.Context, initial State, algorithm string) Result {
+	solver, ok := solverAlgorithms[algorithm]
+	if !ok {
+		return Result{Algorithm: algorithm, Stopped: "algoritmo desconocido"}
+	}
+	if err := ValidateState(initial); err != nil {
+		return Result{Algorithm: algorithm, Stopped: err.Error()}
+	}
+	if err := loadHeuristicTables(); err != nil {
+		return Result{Algorithm: algorithm, Stopped: err.Error()}
+	}
+	ctx, cancel := withSearchTimeout(ctx)
+	defer cancel()
+	start := time.Now()
+	result := solver(ctx, initial)
+	result.Algorithm = algorithm
+	result.Elapsed = time.Since(start)
+	if result.Solved {
+		result.Moves, _ = movesFromPath(result.Path)
+	} else {
+		result.Quality = ""
+	}
+	return result
+}
+
+// withSearchTimeout applies searchTimeout, if set, to ctx.
+func withSearchTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if searchTimeout > 0 {
+		return context.WithTimeout(ctx, searchTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// searchNode is a state stored by the best-first and breadth-first searches. parent is
+// the index of the node it was generated from in the nodes slice, or -1 for the root.
+type searchNode struct {
+	state  PackedState
+	g      int32
+	parent int32
+	f      float64
+}
+
+// nodePath rebuilds the path from the root to the node at index.
+func nodePath(nodes []searchNode, index int32) []State {
+	var path []State
+	for ; index != -1; index = nodes[index].parent {
+		path = append(path, nodes[index].state.unpack())
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// openList is a priority queue of node indices ordered by f, breaking ties in favor of
+// the deeper node, which is usually closer to the goal.
+type openList struct {
+	nodes   *[]searchNode
+	indices []int32
+}
+
+func (o openList) Len() int { return len(o.indices) }
+func (o openList) Less(a, b int) bool {
+	na, nb := (*o.nodes)[o.indices[a]], (*o.nodes)[o.indices[b]]
+	if na.f != nb.f {
+		return na.f < nb.f
+	}
+	return na.g > nb.g
+}
+func (o openList) Swap(a, b int)       { o.indices[a], o.indices[b] = o.indices[b], o.indices[a] }
+func (o *openList) Push(x interface{}) { o.indices = append(o.indices, x.(int32)) }
+func (o *openList) Pop() interface{} {
+	last := o.indices[len(o.indices)-1]
+	o.indices = o.indices[:len(o.indices)-1]
+	return last
+}
+
+// weightedAStar runs A* with f = g + weight*h and a closed set. With weight 1 and an
+// admissible heuristic the solution is optimal; with a larger weight it is found faster
+// and is at most weight times longer than the optimal one. The search gives up once it
+// stores more than maxStates states.
+func weightedAStar(ctx context.Context, initial State, weight float64) Result {
+	root := packState(initial)
+	nodes := []searchNode{{state: root, parent: -1}}
+	rootH := newHeuristicComponents(root).value(root)
+	nodes[0].f = weight * float64(rootH)
+	limits := newSearchLimits(ctx)
+	reported := 0
+	// bestIndex is the node with the lowest heuristic, reported if the search stops.
+	bestIndex, bestH := int32(0), rootH
+
+	open := &openList{nodes: &nodes}
+	heap.Push(open, int32(0))
+	// bestG holds the lowest g found for each state; a state is expanded again only
+	// if it is reached with a lower g, which can happen with inconsistent heuristics.
+	bestG := map[uint64]int32{root.tiles: 0}
+
+	result := Result{Quality: weightedQuality(weight)}
+	for open.Len() > 0 {
+		index := heap.Pop(open).(int32)
+		node := nodes[index]
+		if node.g > bestG[node.state.tiles] {
+			continue
+		}
+		if node.state.isGoal() {
+			result.Solved = true
+			result.Path = nodePath(nodes, index)
+			return result
+		}
+		result.Expanded++
+
+		h := newHeuristicComponents(node.state)
+		for _, m := range legalMoves[node.state.blank] {
+			child, tile, from, _ := node.state.slide(m)
+			g := node.g + 1
+			if best, seen := bestG[child.tiles]; seen && best <= g {
+				continue
+			}
+			if limits.exceeded(result.Generated, &reported) {
+				result.Stopped = limits.reason
+				result.BestPath, result.BestH = nodePath(nodes, bestIndex), bestH
+				return result
+			}
+			result.Generated++
+			if len(nodes) >= maxStates {
+				result.Stopped = fmt.Sprintf("se alcanzó el límite de %d estados en memoria", maxStates)
+				result.BestPath, result.BestH = nodePath(nodes, bestIndex), bestH
+				return result
+			}
+			childH := h.update(child, tile, from, int(node.state.blank)).value(child)
+			bestG[child.tiles] = g
+			nodes = append(nodes, searchNode{
+				state:  child,
+				g:      g,
+				parent: index,
+				f:      float64(g) + weight*float64(childH),
+			})
+			if childH < bestH {
+				bestIndex, bestH = int32(len(nodes)-1), childH
+			}
+			heap.Push(open, int32(len(nodes)-1))
+		}
+	}
+	result.Stopped = "se agotaron los estados"
+	return result
+}
+
+// weightedQuality describes the guarantee of a weighted A* solution.
+func weightedQuality(weight float64) string {
+	if !heuristicAdmissible() {
+		return "heuristic, possibly suboptimal"
+	}
+	if weight == 1 {
+		return "proven optimal"
+	}
+	return fmt.Sprintf("bounded suboptimal (at most %.2f x optimal)", weight)
+}
+
+// breadthFirstSearch explores the states level by level without any heuristic, so the
+// first solution found is optimal. It is only practical for short instances because it
+// keeps every visited state in memory, up to maxStates.
+func breadthFirstSearch(ctx context.Context, initial State) Result {
+	root := packState(initial)
+	nodes := []searchNode{{state: root, parent: -1}}
+	visited := map[uint64]bool{root.tiles: true}
+	result := Result{Quality: "proven optimal"}
+	limits := newSearchLimits(ctx)
+	reported := 0
+
+	if root.isGoal() {
+		result.Solved = true
+		result.Path = nodePath(nodes, 0)
+		return result
+	}
+	for head := int32(0); int(head) < len(nodes); head++ {
+		node := nodes[head]
+		result.Expanded++
+		for _, m := range legalMoves[node.state.blank] {
+			child, _, _, _ := node.state.slide(m)
+			if visited[child.tiles] {
+				continue
+			}
+			if limits.exceeded(result.Generated, &reported) {
+				result.Stopped = limits.reason
+				return result
+			}
+			result.Generated++
+			if len(nodes) >= maxStates {
+				result.Stopped = fmt.Sprintf("se alcanzó el límite de %d estados en memoria", maxStates)
+				return result
+			}
+			visited[child.tiles] = true
+			nodes = append(nodes, searchNode{state: child, g: node.g + 1, parent: head})
+			if child.isGoal() {
+				result.Solved = true
+				result.Path = nodePath(nodes, int32(len(nodes)-1))
+				return result
+			}
+		}
+	}
+	result.Stopped = "se agotaron los estados"
+	return result
+}
+
+// rbfsChild is a successor considered by recursiveBestFirstSearch with its backed-up f value.
+type rbfsChild struct {
+	state PackedState
+	move  Move
+	h     heuristicComponents
+	f     int
+}
+
+// recursiveBestFirstSearch runs RBFS (Korf, 1993): a best-first search in linear memory
+// that remembers, for each subtree it abandons, the best f value found below it.
+func recursiveBestFirstSearch(ctx context.Context, initial State) Result {
+	root := packState(initial)
+	result := Result{Quality: weightedQuality(1)}
+	rootH := newHeuristicComponents(root)
+	path := []PackedState{root}
+	limits := newSearchLimits(ctx)
+	reported := 0
+	var best partialPath
+
+	var rbfs func(state PackedState, h heuristicComponents, g, f, bound int, prevMove *Move) (bool, int)
+	rbfs = func(state PackedState, h heuristicComponents, g, f, bound int, prevMove *Move) (bool, int) {
+		if state.isGoal() {
+			return true, f
+		}
+		if limits.stopped() {
+			return false, math.MaxInt32
+		}
+		result.Expanded++
+		best.offer(h.value(state), path)
+		staticF := g + h.value(state)
+
+		var children []rbfsChild
+		for _, m := range legalMoves[state.blank] {
+			if prevMove != nil && m == opposite(*prevMove) {
+				continue
+			}
+			child, tile, from, _ := state.slide(m)
+			if limits.exceeded(result.Generated, &reported) {
+				return false, math.MaxInt32
+			}
+			result.Generated++
+			childH := h.update(child, tile, from, int(state.blank))
+			childF := g + 1 + childH.value(child)
+			// A node whose f was backed up from a previous visit passes it on to its children.
+			if staticF < f && childF < f {
+				childF = f
+			}
+			children = append(children, rbfsChild{state: child, move: m, h: childH, f: childF})
+		}
+		if len(children) == 0 {
+			return false, math.MaxInt32
+		}
+
+		for {
+			sort.SliceStable(children, func(a, b int) bool { return children[a].f < children[b].f })
+			best := &children[0]
+			if best.f > bound || best.f == math.MaxInt32 {
+				return false, best.f
+			}
+			alternative := bound
+			if len(children) > 1 && children[1].f < alternative {
+				alternative = children[1].f
+			}
+			path = append(path, best.state)
+			m := best.move
+			solved, backedUp := rbfs(best.state, best.h, g+1, best.f, alternative, &m)
+			if solved {
+				return true, backedUp
+			}
+			path = path[:len(path)-1]
+			best.f = backedUp
+		}
+	}
+
+	if solved, _ := rbfs(root, rootH, 0, rootH.value(root), math.MaxInt32-1, nil); solved {
+		result.Solved = true
+		result.Path = unpackPath(path)
+	} else {
+		result.Stopped = "se agotaron los estados"
+		if limits.stopped() {
+			result.Stopped = limits.reason
+		}
+		result.setPartial(best)
+	}
+	return result
+}
@@ -0,0 +1,108 @@
+package puzzle
+
+import (
+	"context"
+	"testing"
+)
+
+// TestAlgorithmsOptimal checks that every selectable algorithm finds the optimal length
+// of the idaInstances up to maxLength moves, since breadth-first search cannot reach the
+// longest ones in reasonable time and memory.
+func TestAlgorithmsOptimal(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		maxLength int
+	}{
+		{"idastar", 40},
+		{"astar", 40},
+		{"bfs", 10},
+		{"rbfs", 40},
+		{"anytime", 40},
+	}
+	for _, test := range tests {
+		t.Run(test.algorithm, func(t *testing.T) {
+			for _, instance := range idaInstances {
+				if instance.length > test.maxLength {
+					continue
+				}
+				b, err := ParseBoard(instance.board, 4, 4)
+				if err != nil {
+					t.Fatal(err)
+				}
+				result, err := Solve(context.Background(), b, Options{Algorithm: test.algorithm, Heuristic: HeuristicAdmissible})
+				if err != nil {
+					t.Fatalf("%s: %v", instance.board, err)
+				}
+				if !result.Solved || result.Length != instance.length {
+					t.Errorf("%s: solved %v in %d moves, want %d", instance.board, result.Solved, result.Length, instance.length)
+				}
+				if result.Quality != "proven optimal" {
+					t.Errorf("%s: quality %q, want proven optimal", instance.board, result.Quality)
+				}
+				if _, err := BoardMoves(result.BoardPath); err != nil {
+					t.Errorf("%s: invalid path: %v", instance.board, err)
+				}
+			}
+		})
+	}
+}
+
+// TestWeightedAStarBound checks that the solutions of weighted A* with an admissible
+// heuristic are at most weight times longer than the optimal ones.
+func TestWeightedAStarBound(t *testing.T) {
+	for _, weight := range []float64{1.5, 2, 3} {
+		for _, instance := range idaInstances {
+			b, err := ParseBoard(instance.board, 4, 4)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := Solve(context.Background(), b, Options{Algorithm: "wastar", Weight: weight, Heuristic: HeuristicAdmissible})
+			if err != nil {
+				t.Fatalf("%s: %v", instance.board, err)
+			}
+			if !result.Solved || result.Length < instance.length || float64(result.Length) > weight*float64(instance.length) {
+				t.Errorf("weight %v, %s: solved %v in %d moves, optimal %d", weight, instance.board, result.Solved, result.Length, instance.length)
+			}
+		}
+	}
+}
+
+// TestAnytimeStoppedKeepsSolution checks that anytime stopped after its first solution
+// keeps it, reports the improvements and bounds the optimal length from below.
+func TestAnytimeStoppedKeepsSolution(t *testing.T) {
+	for _, instance := range idaInstances[1:] {
+		b, err := ParseBoard(instance.board, 4, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var improved []Improvement
+		opts := Options{
+			Algorithm: "anytime",
+			Weight:    3,
+			Heuristic: HeuristicAdmissible,
+			MaxNodes:  20000,
+			Improved:  func(i Improvement) { improved = append(improved, i) },
+		}
+		result, err := Solve(context.Background(), b, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Solved || result.Stopped != "" || result.Generated != opts.MaxNodes {
+			t.Fatalf("%s: solved %v, stopped %q after %d nodes", instance.board, result.Solved, result.Stopped, result.Generated)
+		}
+		if result.LowerBound == 0 || result.LowerBound > instance.length || result.Length < instance.length {
+			t.Errorf("%s: length %d and lower bound %d, optimal %d", instance.board, result.Length, result.LowerBound, instance.length)
+		}
+		if len(improved) == 0 || len(improved) != len(result.Improvements) {
+			t.Fatalf("%s: %d improvements reported, %d in the result", instance.board, len(improved), len(result.Improvements))
+		}
+		for i, improvement := range result.Improvements {
+			if improvement != improved[i] || i > 0 && improvement.Length >= result.Improvements[i-1].Length {
+				t.Errorf("%s: improvement %d is %+v, reported %+v", instance.board, i, improvement, improved[i])
+			}
+		}
+		if last := result.Improvements[len(result.Improvements)-1]; last.Length != result.Length {
+			t.Errorf("%s: last improvement of %d moves, solution of %d", instance.board, last.Length, result.Length)
+		}
+	}
+}
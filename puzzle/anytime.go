@@ -0,0 +1,147 @@
+package puzzle
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"time"
+)
+
+// anytimeWeightedAStar runs Anytime Weighted A* (Hansen and Zhou, 2007). The weighted
+// heuristic finds a first solution quickly; the search then keeps expanding the open list,
+// pruning every node whose unweighted f cannot beat the best solution so far, and reports
+// each shorter solution as soon as it is found. It stops when the time budget expires or
+// when the open list is empty, which proves the last solution optimal if the heuristic is
+// admissible. The context, Options.MaxNodes and maxStates stop it like the budget does;
+// a solution found before is kept, with a lower bound of the optimal length.
+func anytimeWeightedAStar(ctx context.Context, initial State, weight float64, budget time.Duration) Result {
+	start := time.Now()
+	root := packState(initial)
+	rootH := newHeuristicComponents(root).value(root)
+	nodes := []searchNode{{state: root, parent: -1, f: weight * float64(rootH)}}
+	// hValues keeps the unweighted heuristic of each node to prune against the incumbent.
+	hValues := []int32{int32(rootH)}
+
+	open := &openList{nodes: &nodes}
+	heap.Push(open, int32(0))
+	bestG := map[uint64]int32{root.tiles: 0}
+
+	limits := newSearchLimits(ctx)
+	reported := 0
+	bestIndex, bestH := int32(0), rootH
+
+	result := Result{Quality: "heuristic, possibly suboptimal"}
+	incumbent := int32(-1)
+	improve := func(index int32) {
+		incumbent = index
+		result.Solved = true
+		result.Path = nodePath(nodes, index)
+		elapsed := time.Since(start)
+		improvement := Improvement{
+			Length:    len(result.Path) - 1,
+			Generated: result.Generated,
+			Elapsed:   elapsed,
+			ElapsedMs: float64(elapsed) / float64(time.Millisecond),
+		}
+		result.Improvements = append(result.Improvements, improvement)
+		if improvementFunc != nil {
+			improvementFunc(improvement)
+		}
+		logf("Solución encontrada: %d movimientos, estados generados: %d, tiempo: %v\n",
+			improvement.Length, result.Generated, elapsed)
+	}
+	// stop ends the search early. Without a solution it reports why, with the best partial
+	// path; with one, the solution is kept and the lowest unweighted f of the open list and
+	// of current, the node being expanded (-1 if none), bounds the optimal length.
+	stop := func(reason string, current int32) Result {
+		if !result.Solved {
+			result.Stopped = reason
+			result.BestPath, result.BestH = nodePath(nodes, bestIndex), bestH
+			return result
+		}
+		if !heuristicAdmissible() {
+			return result
+		}
+		bound := nodes[incumbent].g
+		if current != -1 {
+			open.indices = append(open.indices, current)
+		}
+		for _, index := range open.indices {
+			if f := nodes[index].g + hValues[index]; f < bound {
+				bound = f
+			}
+		}
+		result.LowerBound = int(bound)
+		if result.LowerBound == len(result.Path)-1 {
+			result.Quality = "proven optimal"
+		}
+		return result
+	}
+	if root.isGoal() {
+		improve(0)
+	}
+
+	// pops counts every node taken from the open list, pruned or not, so the budget is
+	// checked every nodeCheckInterval pops even while no node is expanded.
+	pops := 0
+	for open.Len() > 0 {
+		pops++
+		if budget > 0 && pops%nodeCheckInterval == 0 && time.Since(start) > budget {
+			return stop("se agotó el tiempo disponible", -1)
+		}
+		index := heap.Pop(open).(int32)
+		node := nodes[index]
+		if node.g > bestG[node.state.tiles] {
+			continue
+		}
+		if incumbent != -1 && node.g+hValues[index] >= nodes[incumbent].g {
+			continue
+		}
+		result.Expanded++
+
+		h := newHeuristicComponents(node.state)
+		for _, m := range legalMoves[node.state.blank] {
+			child, tile, from, _ := node.state.slide(m)
+			g := node.g + 1
+			if best, seen := bestG[child.tiles]; seen && best <= g {
+				continue
+			}
+			if limits.exceeded(result.Generated, &reported) {
+				return stop(limits.reason, index)
+			}
+			result.Generated++
+			childH := int32(h.update(child, tile, from, int(node.state.blank)).value(child))
+			if incumbent != -1 && g+childH >= nodes[incumbent].g {
+				continue
+			}
+			if len(nodes) >= maxStates {
+				return stop(fmt.Sprintf("se alcanzó el límite de %d estados en memoria", maxStates), index)
+			}
+			bestG[child.tiles] = g
+			nodes = append(nodes, searchNode{
+				state:  child,
+				g:      g,
+				parent: index,
+				f:      float64(g) + weight*float64(childH),
+			})
+			hValues = append(hValues, childH)
+			if int(childH) < bestH {
+				bestIndex, bestH = int32(len(nodes)-1), int(childH)
+			}
+			if child.isGoal() {
+				improve(int32(len(nodes) - 1))
+				continue
+			}
+			heap.Push(open, int32(len(nodes)-1))
+		}
+	}
+
+	// The open list is empty: no path shorter than the incumbent is left.
+	if result.Solved && heuristicAdmissible() {
+		result.Quality = "proven optimal"
+	}
+	if !result.Solved {
+		result.Stopped = "se agotaron los estados"
+	}
+	return result
+}
@@ -0,0 +1,554 @@
+package puzzle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Board sizes accepted by ParseSize and Board.Validate.
+const (
+	minBoardSide = 2
+	maxBoardSide = 8
+)
+
+// Board is a sliding puzzle of any size. Tiles holds the rows one after another and 0 is
+// the blank; the goal is the current goal of its size (see goalFor).
+// 4x4 boards are solved with the State solvers; the other sizes with boardIDAStar.
+type Board struct {
+	Rows, Cols int
+	Tiles      []int
+}
+
+// ParseSize reads a board size written as "RxC", such as "3x3" or "3x5".
+func ParseSize(text string) (rows, cols int, err error) {
+	parts := strings.Split(strings.ToLower(text), "x")
+	if len(parts) == 2 {
+		r, err1 := strconv.Atoi(parts[0])
+		c, err2 := strconv.Atoi(parts[1])
+		if err1 == nil && err2 == nil && r >= minBoardSide && r <= maxBoardSide &&
+			c >= minBoardSide && c <= maxBoardSide {
+			return r, c, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("tamaño inválido: %s (filas x columnas, entre %d y %d, por ejemplo 3x3)", text, minBoardSide, maxBoardSide)
+}
+
+// goalBoard returns the standard goal of a rows x cols board.
+func goalBoard(rows, cols int) Board {
+	b := Board{Rows: rows, Cols: cols, Tiles: make([]int, rows*cols)}
+	for i := range b.Tiles[:len(b.Tiles)-1] {
+		b.Tiles[i] = i + 1
+	}
+	return b
+}
+
+// ParseBoard convierte una línea con rows*cols números separados por espacios en un
+// tablero y verifica que sea una permutación de 0..rows*cols-1.
+func ParseBoard(input string, rows, cols int) (Board, error) {
+	b := Board{Rows: rows, Cols: cols}
+	fields := strings.Fields(input)
+	if len(fields) != rows*cols {
+		return b, fmt.Errorf("Debe ingresar %d números", rows*cols)
+	}
+	for _, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return b, fmt.Errorf("Error al convertir: %s", field)
+		}
+		b.Tiles = append(b.Tiles, n)
+	}
+	return b, b.Validate()
+}
+
+// boardFromState converts a 4x4 state into a Board.
+func boardFromState(state State) Board {
+	b := Board{Rows: 4, Cols: 4, Tiles: make([]int, 0, 16)}
+	for _, row := range state {
+		b.Tiles = append(b.Tiles, row[:]...)
+	}
+	return b
+}
+
+// boardsFromStates converts a path of 4x4 states into Boards (nil for an empty path).
+func boardsFromStates(path []State) []Board {
+	if len(path) == 0 {
+		return nil
+	}
+	boards := make([]Board, len(path))
+	for i, state := range path {
+		boards[i] = boardFromState(state)
+	}
+	return boards
+}
+
+// is4x4 reports whether the board can be handled by the 4x4 solvers.
+func (b Board) is4x4() bool {
+	return b.Rows == 4 && b.Cols == 4
+}
+
+// State converts a 4x4 board into a State.
+func (b Board) State() State {
+	var state State
+	for i, v := range b.Tiles {
+		state[i/4][i%4] = v
+	}
+	return state
+}
+
+// Validate checks that the size is supported and that the tiles are a permutation of
+// 0..Rows*Cols-1.
+func (b Board) Validate() error {
+	if b.Rows < minBoardSide || b.Rows > maxBoardSide || b.Cols < minBoardSide || b.Cols > maxBoardSide {
+		return fmt.Errorf("tamaño inválido: %dx%d (entre %d y %d)", b.Rows, b.Cols, minBoardSide, maxBoardSide)
+	}
+	if len(b.Tiles) != b.Rows*b.Cols {
+		return fmt.Errorf("el tablero %dx%d tiene %d casillas", b.Rows, b.Cols, len(b.Tiles))
+	}
+	return validateTiles(b.Tiles)
+}
+
+// blank returns the index of the blank cell.
+func (b Board) blank() int {
+	for i, v := range b.Tiles {
+		if v == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// Goal returns the goal of the board: the goal of the configuration (Options.Goal) if it
+// has the board size and the standard goal otherwise.
+func (b Board) Goal() *Goal {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return b.goal()
+}
+
+// goal is Goal without the lock.
+func (b Board) goal() *Goal {
+	return goalFor(b.Rows, b.Cols)
+}
+
+// IsGoal reports whether the board is solved.
+func (b Board) IsGoal() bool {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return b.isGoal()
+}
+
+// isGoal is IsGoal without the lock.
+func (b Board) isGoal() bool {
+	return equalTiles(b.Tiles, b.goal().Tiles)
+}
+
+// IsSolvable reports whether the goal can be reached from the board (see Goal.reachable),
+// or with the obstacles of its size, Obstacles.reachable. With walls that break the
+// parity rule it searches, and it returns ErrUndecided if the search gives up and
+// ctx.Err() if ctx ends first.
+func (b Board) IsSolvable(ctx context.Context) (bool, error) {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return b.isSolvable(ctx)
+}
+
+// isSolvable is IsSolvable without the lock.
+func (b Board) isSolvable(ctx context.Context) (bool, error) {
+	if o := b.obstacles(); o != nil {
+		return o.reachable(ctx, b, b.goal())
+	}
+	return b.goal().reachable(b.Tiles), nil
+}
+
+// CheckObstacles verifies that the blocked cells of the configuration (Options.Obstacles)
+// hold the tiles of the goal. Boards of other sizes always pass.
+func (b Board) CheckObstacles() error {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return b.checkObstacles()
+}
+
+// checkObstacles is CheckObstacles without the lock.
+func (b Board) checkObstacles() error {
+	if o := b.obstacles(); o != nil {
+		return o.check(b, b.goal())
+	}
+	return nil
+}
+
+// obstacles returns the obstacles of the configuration (Options.Obstacles) if they have
+// the board size and nil otherwise.
+func (b Board) obstacles() *Obstacles {
+	return obstaclesFor(b.Rows, b.Cols)
+}
+
+// Inversions counts the pairs of tiles in the wrong order, ignoring the blank.
+func (b Board) Inversions() int {
+	return countInversions(b.Tiles)
+}
+
+// BlankRowFromBottom returns the row of the blank counted from the bottom, starting at 1.
+// With Inversions it decides IsSolvable.
+func (b Board) BlankRowFromBottom() int {
+	return findBlankPosition(b.Tiles, b.Rows, b.Cols)
+}
+
+// move slides the blank in direction m and returns the new board (sharing nothing with b).
+// The blank cannot leave the board, enter a blocked cell or cross a wall.
+func (b Board) move(m Move) (Board, bool) {
+	blank := b.blank()
+	i, j := blank/b.Cols+moveOffsets[m][0], blank%b.Cols+moveOffsets[m][1]
+	if i < 0 || i >= b.Rows || j < 0 || j >= b.Cols {
+		return b, false
+	}
+	if o := b.obstacles(); o != nil && o.neighbor(blank, m) == -1 {
+		return b, false
+	}
+	next := Board{Rows: b.Rows, Cols: b.Cols, Tiles: append([]int(nil), b.Tiles...)}
+	next.Tiles[blank], next.Tiles[i*b.Cols+j] = next.Tiles[i*b.Cols+j], 0
+	return next, true
+}
+
+// inside reports whether moving the blank with m keeps it on the board.
+func (b Board) inside(m Move) bool {
+	blank := b.blank()
+	i, j := blank/b.Cols+moveOffsets[m][0], blank%b.Cols+moveOffsets[m][1]
+	return i >= 0 && i < b.Rows && j >= 0 && j < b.Cols
+}
+
+// Manhattan returns the sum of the distances of every tile to its goal cell.
+func (b Board) Manhattan() int {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return b.manhattan()
+}
+
+// manhattan is Manhattan without the lock.
+func (b Board) manhattan() int {
+	g := b.goal()
+	distance := 0
+	for cell, tile := range b.Tiles {
+		if tile != 0 {
+			distance += abs(cell/b.Cols-g.row(tile)) + abs(cell%b.Cols-g.col(tile))
+		}
+	}
+	return distance
+}
+
+// TrueDistance returns the sum of the true distances of every tile to its goal cell
+// around the obstacles of the board size (see Obstacles), or the Manhattan Distance
+// without obstacles.
+func (b Board) TrueDistance() int {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return b.trueDistance()
+}
+
+// trueDistance is TrueDistance without the lock.
+func (b Board) trueDistance() int {
+	o := b.obstacles()
+	if o == nil {
+		return b.manhattan()
+	}
+	g := b.goal()
+	distance := 0
+	for cell, tile := range b.Tiles {
+		if tile != 0 {
+			distance += o.distance(cell, g.cell(tile))
+		}
+	}
+	return distance
+}
+
+// verticalManhattan is the part of Manhattan that counts rows.
+func (b Board) verticalManhattan() int {
+	g := b.goal()
+	distance := 0
+	for cell, tile := range b.Tiles {
+		if tile != 0 {
+			distance += abs(cell/b.Cols - g.row(tile))
+		}
+	}
+	return distance
+}
+
+// multiTileBound is the heuristic of the multi-tile metric. A vertical move slides at
+// most rows-1 tiles one row each, so it lowers the vertical Manhattan Distance by at most
+// rows-1, and the multi-tile walking distance of the rows (0 without table) also bounds
+// the vertical moves; the same holds for the horizontal moves and the columns.
+func multiTileBound(rows, cols, vertical, horizontal, rowWalk, colWalk int) int {
+	v := (vertical + rows - 2) / (rows - 1)
+	if rowWalk > v {
+		v = rowWalk
+	}
+	h := (horizontal + cols - 2) / (cols - 1)
+	if colWalk > h {
+		h = colWalk
+	}
+	return v + h
+}
+
+// LinearConflict is the admissible linear conflict of every row and column (see lineConflict).
+func (b Board) LinearConflict() int {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return b.linearConflict()
+}
+
+// linearConflict is LinearConflict without the lock.
+func (b Board) linearConflict() int {
+	conflict := 0
+	for row := 0; row < b.Rows; row++ {
+		conflict += b.rowConflict(row)
+	}
+	for col := 0; col < b.Cols; col++ {
+		conflict += b.colConflict(col)
+	}
+	return conflict
+}
+
+// rowConflict is the linear conflict of one row.
+func (b Board) rowConflict(row int) int {
+	g := b.goal()
+	var goals []int
+	for col := 0; col < b.Cols; col++ {
+		if tile := b.Tiles[row*b.Cols+col]; tile != 0 && g.row(tile) == row {
+			goals = append(goals, g.col(tile))
+		}
+	}
+	return lineConflict(goals)
+}
+
+// colConflict is the linear conflict of one column.
+func (b Board) colConflict(col int) int {
+	g := b.goal()
+	var goals []int
+	for row := 0; row < b.Rows; row++ {
+		if tile := b.Tiles[row*b.Cols+col]; tile != 0 && g.col(tile) == col {
+			goals = append(goals, g.row(tile))
+		}
+	}
+	return lineConflict(goals)
+}
+
+// walkingCounts returns the count matrices of the rows and of the columns of the board:
+// rowCounts[i*Rows+g] is how many tiles of goal row g are in row i, and colCounts the same
+// for the columns.
+func (b Board) walkingCounts() (rowCounts, colCounts []int) {
+	g := b.goal()
+	rowCounts = make([]int, b.Rows*b.Rows)
+	colCounts = make([]int, b.Cols*b.Cols)
+	for cell, tile := range b.Tiles {
+		if tile != 0 {
+			rowCounts[cell/b.Cols*b.Rows+g.row(tile)]++
+			colCounts[cell%b.Cols*b.Cols+g.col(tile)]++
+		}
+	}
+	return rowCounts, colCounts
+}
+
+// WalkingDistance returns the walking distance of the board and whether the tables of its
+// goal are loaded (see prepareWalkingTables). An axis without table counts 0.
+func (b Board) WalkingDistance() (int, bool) {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return b.walkingDistance()
+}
+
+// walkingDistance is WalkingDistance without the lock.
+func (b Board) walkingDistance() (int, bool) {
+	rowTable, colTable := goalWalkingTables(b.goal())
+	if rowTable == nil && colTable == nil {
+		return 0, false
+	}
+	rowCounts, colCounts := b.walkingCounts()
+	blank := b.blank()
+	distance := 0
+	if rowTable != nil {
+		distance += rowTable.lookup(rowCounts, blank/b.Cols)
+	}
+	if colTable != nil {
+		distance += colTable.lookup(colCounts, blank%b.Cols)
+	}
+	return distance, true
+}
+
+// Heuristic is the heuristic used for the board: the selected one for 4x4 boards and
+// max(Walking Distance, Manhattan Distance + Linear Conflict) (admissible) for the other
+// sizes, or only Manhattan Distance + Linear Conflict without walking distance tables.
+// In the multi-tile metric it is multiTileBound for every size, and with obstacles
+// max(Walking Distance, TrueDistance).
+func (b Board) Heuristic() int {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return b.heuristic()
+}
+
+// heuristic is Heuristic without the lock.
+func (b Board) heuristic() int {
+	if b.is4x4() && moveMetric == MetricSingle && b.obstacles() == nil {
+		return heuristic(b.State())
+	}
+	return b.heuristicReport().Total
+}
+
+// HeuristicReport computes the heuristic components of the board: the ones of the selected
+// heuristic for 4x4 boards and the ones of Board.Heuristic for the other sizes, or the ones
+// of multiTileBound in the multi-tile metric or of max(Walking Distance, TrueDistance) with
+// obstacles.
+func (b Board) HeuristicReport() HeuristicReport {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return b.heuristicReport()
+}
+
+// heuristicReport is HeuristicReport without the lock.
+func (b Board) heuristicReport() HeuristicReport {
+	if moveMetric == MetricMulti {
+		return b.multiTileReport()
+	}
+	if b.obstacles() != nil {
+		return b.obstacleReport()
+	}
+	if b.is4x4() {
+		return heuristicReport(b.State())
+	}
+	manhattanDistanceValue := b.manhattan()
+	linearConflictValue := b.linearConflict()
+	report := HeuristicReport{
+		Formula:        "manhattanDistanceValue + linearConflictValue",
+		Manhattan:      &manhattanDistanceValue,
+		LinearConflict: &linearConflictValue,
+		Total:          manhattanDistanceValue + linearConflictValue,
+	}
+	if walkingDistanceValue, ok := b.walkingDistance(); ok {
+		report.Formula = "max(walkingDistanceValue, manhattanDistanceValue + linearConflictValue)"
+		report.WalkingDistance = &walkingDistanceValue
+		if walkingDistanceValue > report.Total {
+			report.Total = walkingDistanceValue
+		}
+	}
+	return report
+}
+
+// multiTileReport computes the components of multiTileBound.
+func (b Board) multiTileReport() HeuristicReport {
+	manhattanDistanceValue := b.manhattan()
+	vertical := b.verticalManhattan()
+	report := HeuristicReport{
+		Formula:   "ceil(verticalManhattan / (rows-1)) + ceil(horizontalManhattan / (cols-1))",
+		Manhattan: &manhattanDistanceValue,
+	}
+	rowWalk, colWalk := 0, 0
+	rowTable, colTable := goalWalkingTables(b.goal())
+	if rowTable != nil || colTable != nil {
+		rowCounts, colCounts := b.walkingCounts()
+		blank := b.blank()
+		if rowTable != nil {
+			rowWalk = rowTable.lookup(rowCounts, blank/b.Cols)
+		}
+		if colTable != nil {
+			colWalk = colTable.lookup(colCounts, blank%b.Cols)
+		}
+		walkingDistanceValue := rowWalk + colWalk
+		report.Formula = "max(rowWalkingDistance, ceil(verticalManhattan / (rows-1))) + max(colWalkingDistance, ceil(horizontalManhattan / (cols-1)))"
+		report.WalkingDistance = &walkingDistanceValue
+	}
+	report.Total = multiTileBound(b.Rows, b.Cols, vertical, manhattanDistanceValue-vertical, rowWalk, colWalk)
+	return report
+}
+
+// obstacleReport computes the components of the heuristic of a board with obstacles. The
+// linear conflicts assume free rows and columns, so only the true distances and the
+// walking distance, which relaxes the obstacles, are used.
+func (b Board) obstacleReport() HeuristicReport {
+	trueDistanceValue := b.trueDistance()
+	report := HeuristicReport{
+		Formula:      "trueDistanceValue",
+		TrueDistance: &trueDistanceValue,
+		Total:        trueDistanceValue,
+	}
+	if walkingDistanceValue, ok := b.walkingDistance(); ok {
+		report.Formula = "max(walkingDistanceValue, trueDistanceValue)"
+		report.WalkingDistance = &walkingDistanceValue
+		if walkingDistanceValue > report.Total {
+			report.Total = walkingDistanceValue
+		}
+	}
+	return report
+}
+
+// Matrix returns the tiles as a slice of rows.
+func (b Board) Matrix() [][]int {
+	matrix := make([][]int, b.Rows)
+	for row := range matrix {
+		matrix[row] = b.Tiles[row*b.Cols : (row+1)*b.Cols]
+	}
+	return matrix
+}
+
+// MarshalJSON writes the board as a matrix, like a State.
+func (b Board) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.Matrix())
+}
+
+// String writes the tiles separated by spaces, as ParseBoard reads them.
+func (b Board) String() string {
+	parts := make([]string, len(b.Tiles))
+	for i, v := range b.Tiles {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, " ")
+}
+
+// BoardMoves returns the moves of the blank that follow a path of boards.
+func BoardMoves(path []Board) ([]Move, error) {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return boardMoves(path)
+}
+
+// boardMoves is BoardMoves without the lock.
+func boardMoves(path []Board) ([]Move, error) {
+	moves := make([]Move, 0, len(path))
+	for k := 1; k < len(path); k++ {
+		found := false
+		for m := Up; m <= Right; m++ {
+			if next, ok := path[k-1].move(m); ok && equalTiles(next.Tiles, path[k].Tiles) {
+				moves = append(moves, m)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("boards %d and %d are not one move apart", k-1, k)
+		}
+	}
+	return moves, nil
+}
+
+// replayMoves returns the boards visited by applying moves from start.
+func replayMoves(start Board, moves []Move) []Board {
+	path := []Board{start}
+	for _, m := range moves {
+		next, _ := path[len(path)-1].move(m)
+		path = append(path, next)
+	}
+	return path
+}
+
+// equalTiles reports whether two boards have the same tiles.
+func equalTiles(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
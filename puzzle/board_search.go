@@ -0,0 +1,294 @@
+package puzzle
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// boardSearcher runs IDA* on a Board of any size. The blank moves in place and each move
+// only updates the Manhattan Distance of the moved tile, the linear conflicts of the two
+// rows (or columns) it touches and the walking distance of its axis. With multi (the
+// multi-tile metric) a move slides the blank several cells in one direction. With
+// obstacles the blank cannot enter blocked cells or cross walls, and manhattan holds the
+// true distances of the tiles instead (see Obstacles).
+type boardSearcher struct {
+	rows, cols int
+	goal       *Goal
+	multi      bool
+	obstacles  *Obstacles
+	// linear is whether the heuristic uses the linear conflicts (only in the single-tile
+	// metric without obstacles).
+	linear    bool
+	tiles     []int
+	blank     int
+	manhattan int
+	// vertical is the part of manhattan that counts rows.
+	vertical  int
+	rowConf   []int
+	colConf   []int
+	conflicts int
+	moves     []Move
+
+	// rowTable and colTable are the walking distance tables of the goal (nil if not
+	// loaded); rowWalk and colWalk the distances of rowCounts and colCounts.
+	rowTable, colTable   *wdTable
+	rowCounts, colCounts []int
+	rowWalk, colWalk     int
+
+	generated, expanded int
+	limits              *searchLimits
+	reported            int
+	bestH               int
+	bestMoves           []Move
+}
+
+// newBoardSearcher prepares the search from b, computing every component from scratch.
+func newBoardSearcher(b Board, limits *searchLimits) *boardSearcher {
+	s := &boardSearcher{
+		rows:      b.Rows,
+		cols:      b.Cols,
+		goal:      b.goal(),
+		multi:     moveMetric == MetricMulti,
+		obstacles: b.obstacles(),
+		tiles:     append([]int(nil), b.Tiles...),
+		blank:     b.blank(),
+		manhattan: b.trueDistance(),
+		vertical:  b.verticalManhattan(),
+		rowConf:   make([]int, b.Rows),
+		colConf:   make([]int, b.Cols),
+		limits:    limits,
+		bestH:     math.MaxInt32,
+	}
+	s.linear = !s.multi && s.obstacles == nil
+	for row := range s.rowConf {
+		s.rowConf[row] = b.rowConflict(row)
+		s.conflicts += s.rowConf[row]
+	}
+	for col := range s.colConf {
+		s.colConf[col] = b.colConflict(col)
+		s.conflicts += s.colConf[col]
+	}
+	s.rowTable, s.colTable = goalWalkingTables(s.goal)
+	s.rowCounts, s.colCounts = b.walkingCounts()
+	if s.rowTable != nil {
+		s.rowWalk = s.rowTable.lookup(s.rowCounts, s.blank/s.cols)
+	}
+	if s.colTable != nil {
+		s.colWalk = s.colTable.lookup(s.colCounts, s.blank%s.cols)
+	}
+	return s
+}
+
+// heuristic is max(Walking Distance, Manhattan Distance + Linear Conflict) of the current
+// board, multiTileBound in the multi-tile metric or max(Walking Distance, true distance)
+// with obstacles.
+func (s *boardSearcher) heuristic() int {
+	if s.multi {
+		return multiTileBound(s.rows, s.cols, s.vertical, s.manhattan-s.vertical, s.rowWalk, s.colWalk)
+	}
+	h := s.manhattan
+	if s.linear {
+		h += s.conflicts
+	}
+	if walking := s.rowWalk + s.colWalk; walking > h {
+		h = walking
+	}
+	return h
+}
+
+// board returns the current tiles as a Board that shares them.
+func (s *boardSearcher) board() Board {
+	return Board{Rows: s.rows, Cols: s.cols, Tiles: s.tiles}
+}
+
+// target returns the cell the blank reaches with m, or -1 if it leaves the board or meets
+// an obstacle.
+func (s *boardSearcher) target(m Move) int {
+	if s.obstacles != nil {
+		return s.obstacles.neighbor(s.blank, m)
+	}
+	i, j := s.blank/s.cols+moveOffsets[m][0], s.blank%s.cols+moveOffsets[m][1]
+	if i < 0 || i >= s.rows || j < 0 || j >= s.cols {
+		return -1
+	}
+	return i*s.cols + j
+}
+
+// distance is the Manhattan distance of tile from cell to its goal cell, or the true
+// distance with obstacles.
+func (s *boardSearcher) distance(tile, cell int) int {
+	if s.obstacles != nil {
+		return s.obstacles.distance(cell, s.goal.cell(tile))
+	}
+	return abs(cell/s.cols-s.goal.row(tile)) + abs(cell%s.cols-s.goal.col(tile))
+}
+
+// slide moves the blank to cell and updates the heuristic components. Sliding back to the
+// previous blank cell undoes it.
+func (s *boardSearcher) slide(cell int) {
+	tile := s.tiles[cell]
+	delta := s.distance(tile, s.blank) - s.distance(tile, cell)
+	s.manhattan += delta
+	s.tiles[s.blank], s.tiles[cell] = tile, 0
+	from := s.blank
+	s.blank = cell
+
+	b := s.board()
+	if from%s.cols == cell%s.cols {
+		// The tile changed row: only the two rows change their conflicts.
+		s.vertical += delta
+		for _, row := range [2]int{from / s.cols, cell / s.cols} {
+			if !s.linear {
+				break
+			}
+			s.conflicts -= s.rowConf[row]
+			s.rowConf[row] = b.rowConflict(row)
+			s.conflicts += s.rowConf[row]
+		}
+		if s.rowTable != nil {
+			group := s.goal.row(tile)
+			s.rowCounts[cell/s.cols*s.rows+group]--
+			s.rowCounts[from/s.cols*s.rows+group]++
+			s.rowWalk = s.rowTable.lookup(s.rowCounts, cell/s.cols)
+		}
+	} else {
+		for _, col := range [2]int{from % s.cols, cell % s.cols} {
+			if !s.linear {
+				break
+			}
+			s.conflicts -= s.colConf[col]
+			s.colConf[col] = b.colConflict(col)
+			s.conflicts += s.colConf[col]
+		}
+		if s.colTable != nil {
+			group := s.goal.col(tile)
+			s.colCounts[cell%s.cols*s.cols+group]--
+			s.colCounts[from%s.cols*s.cols+group]++
+			s.colWalk = s.colTable.lookup(s.colCounts, cell%s.cols)
+		}
+	}
+}
+
+// search explores the subtree of the current board with the given bound, like
+// searcher.search. The moves of the solution are left in s.moves, one per tile. In the
+// multi-tile metric each direction is tried with 1, 2, ... cells at the cost of one move,
+// and the direction of prev is skipped too: two moves along the same line are one move.
+func (s *boardSearcher) search(g, bound int, prev Move) (bool, int) {
+	h := s.heuristic()
+	if h < s.bestH {
+		s.bestH = h
+		s.bestMoves = append(s.bestMoves[:0], s.moves...)
+	}
+	f := g + h
+	if f > bound {
+		return false, f
+	}
+	if s.manhattan == 0 {
+		return true, bound
+	}
+	if s.limits.stopped() {
+		return false, math.MaxInt32
+	}
+	s.expanded++
+	minBound := math.MaxInt32
+	for m := Up; m <= Right; m++ {
+		if prev != -1 && (m == opposite(prev) || (s.multi && m == prev)) {
+			continue
+		}
+		steps := 0
+		for cell := s.target(m); cell != -1; cell = s.target(m) {
+			if s.limits.exceeded(s.generated, &s.reported) {
+				return false, math.MaxInt32
+			}
+			s.generated++
+			s.slide(cell)
+			s.moves = append(s.moves, m)
+			steps++
+			solved, t := s.search(g+1, bound, m)
+			if solved {
+				return true, t
+			}
+			if t < minBound {
+				minBound = t
+			}
+			if !s.multi {
+				break
+			}
+		}
+		for ; steps > 0; steps-- {
+			s.moves = s.moves[:len(s.moves)-1]
+			s.slide(s.target(opposite(m)))
+		}
+	}
+	return false, minBound
+}
+
+// boardIDAStar solves a board of any size with IDA* and max(Walking Distance, Manhattan
+// Distance + Linear Conflict), multiTileBound in the multi-tile metric or max(Walking
+// Distance, true distance) with obstacles. All are admissible, so the solution is optimal.
+func boardIDAStar(ctx context.Context, initial Board) Result {
+	start := time.Now()
+	iterations = nil
+	limits := newSearchLimits(ctx)
+	s := newBoardSearcher(initial, limits)
+	bound := s.heuristic()
+	var result Result
+	for {
+		previous := s.generated
+		solved, newBound := s.search(0, bound, -1)
+		result.Generated, result.Expanded, result.Bound = s.generated, s.expanded, bound
+		if limits.stopped() {
+			result.Stopped = limits.reason
+			break
+		}
+		reportIteration(start, bound, newBound, previous, s.generated)
+		if solved {
+			result.Solved, result.Quality = true, "proven optimal"
+			result.Moves = s.moves
+			result.BoardPath = replayMoves(initial, s.moves)
+			break
+		}
+		if newBound == math.MaxInt32 {
+			result.Stopped = "se agotaron los estados"
+			break
+		}
+		bound = newBound
+	}
+	result.Iterations = iterations
+	if !result.Solved {
+		result.BestBoardPath = replayMoves(initial, s.bestMoves)
+		result.BestH = s.bestH
+	}
+	return result
+}
+
+// solveBoard solves a board of any size under a context. 4x4 boards in the single-tile
+// metric and without obstacles go through solveState and the selected algorithm and
+// heuristic; the other sizes, the multi-tile metric and the obstacles only support IDA*.
+// BoardPath and BestBoardPath are filled for every size and Length counts the moves in
+// the current metric.
+func solveBoard(ctx context.Context, b Board, algorithm string) Result {
+	if b.is4x4() && moveMetric == MetricSingle && b.obstacles() == nil {
+		result := solveState(ctx, b.State(), algorithm)
+		result.BoardPath = boardsFromStates(result.Path)
+		result.BestBoardPath = boardsFromStates(result.BestPath)
+		result.Length = len(result.Moves)
+		return result
+	}
+	if err := b.Validate(); err != nil {
+		return Result{Algorithm: algorithm, Stopped: err.Error()}
+	}
+	if algorithm != "idastar" {
+		return Result{Algorithm: algorithm, Stopped: fmt.Sprintf("el algoritmo %s solo está disponible para tableros 4x4", algorithm)}
+	}
+	ctx, cancel := withSearchTimeout(ctx)
+	defer cancel()
+	start := time.Now()
+	result := boardIDAStar(ctx, b)
+	result.Algorithm = algorithm
+	result.Elapsed = time.Since(start)
+	result.Length = MoveCount(result.Moves, moveMetric)
+	return result
+}
@@ -0,0 +1,106 @@
+package puzzle
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// bfsDistances returns the distance from goal of every board reachable from it with
+// Board.move, sliding several cells per move in the multi-tile metric. Every move can be
+// undone, so it is also the distance to the goal.
+func bfsDistances(goal Board, multi bool) map[string]int {
+	distances := map[string]int{tilesKey(goal.Tiles): 0}
+	frontier := []Board{goal}
+	for d := 1; len(frontier) > 0; d++ {
+		var next []Board
+		for _, b := range frontier {
+			for m := Up; m <= Right; m++ {
+				for current, ok := b.move(m); ok; current, ok = current.move(m) {
+					if _, seen := distances[tilesKey(current.Tiles)]; !seen {
+						distances[tilesKey(current.Tiles)] = d
+						next = append(next, current)
+					}
+					if !multi {
+						break
+					}
+				}
+			}
+		}
+		frontier = next
+	}
+	return distances
+}
+
+func TestSolveOptimalAgainstBFS(t *testing.T) {
+	tests := []struct {
+		name           string
+		rows, cols     int
+		goal           string
+		metric         string
+		blocked, walls string
+	}{
+		{name: "3x3", rows: 3, cols: 3},
+		{name: "2x4", rows: 2, cols: 4},
+		{name: "3x3 snake", rows: 3, cols: 3, goal: GoalSnake},
+		{name: "2x3 custom goal", rows: 2, cols: 3, goal: "5 4 3 2 1 0"},
+		{name: "3x3 multi", rows: 3, cols: 3, metric: MetricMulti},
+		{name: "2x4 multi", rows: 2, cols: 4, metric: MetricMulti},
+		{name: "3x3 snake multi", rows: 3, cols: 3, goal: GoalSnake, metric: MetricMulti},
+		{name: "3x3 walls", rows: 3, cols: 3, walls: "0-1,4-7"},
+		{name: "3x3 blocked center", rows: 3, cols: 3, blocked: "4"},
+		{name: "3x3 blocked corner and wall", rows: 3, cols: 3, blocked: "0", walls: "4-5"},
+		{name: "2x4 wall", rows: 2, cols: 4, walls: "1-5"},
+		{name: "3x3 walls snake", rows: 3, cols: 3, goal: GoalSnake, walls: "1-4,3-6"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			obstacles, err := ParseObstacles(test.blocked, test.walls, test.rows, test.cols)
+			if err != nil {
+				t.Fatal(err)
+			}
+			goal, err := GoalLayout(test.goal, test.rows, test.cols)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := Options{Goal: goal, Metric: test.metric, Obstacles: obstacles}
+			if err := Configure(opts); err != nil {
+				t.Fatal(err)
+			}
+			distances := bfsDistances(goal.Board, test.metric == MetricMulti)
+
+			// Random permutations of the free cells: the reachable ones must be solved
+			// with the BFS distance, the others rejected as unsolvable.
+			var free []int
+			for cell := range goal.Tiles {
+				if obstacles == nil || !obstacles.blocked[cell] {
+					free = append(free, cell)
+				}
+			}
+			sort.Ints(free)
+			rng := rand.New(rand.NewSource(1))
+			for i := 0; i < 40; i++ {
+				b := Board{Rows: test.rows, Cols: test.cols, Tiles: append([]int(nil), goal.Tiles...)}
+				for k, p := range rng.Perm(len(free)) {
+					b.Tiles[free[k]] = goal.Tiles[free[p]]
+				}
+				want, reachable := distances[tilesKey(b.Tiles)]
+				result, err := Solve(context.Background(), b, opts)
+				switch {
+				case !reachable:
+					if !errors.Is(err, ErrUnsolvable) {
+						t.Errorf("%v: err = %v, want ErrUnsolvable", b, err)
+					}
+				case err != nil:
+					t.Errorf("%v: %v", b, err)
+				case result.Length != want || result.Quality != "proven optimal":
+					t.Errorf("%v: length %d (%s), want %d", b, result.Length, result.Quality, want)
+				case b.Heuristic() > want:
+					t.Errorf("%v: heuristic %d overestimates %d", b, b.Heuristic(), want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package puzzle
+
+import (
+	"fmt"
+	"hash/crc32"
+	"strings"
+)
+
+// Goal layouts accepted by GoalLayout. Any other value is read as the list of tiles of the goal,
+// row by row, separated by spaces or commas.
+const (
+	GoalStandard   = "standard"
+	GoalBlankFirst = "blank-first"
+	GoalSnake      = "snake"
+	GoalSpiral     = "spiral"
+)
+
+// Goal is the board a search must reach. cells[tile] is the cell where tile must end up.
+type Goal struct {
+	Board
+	cells []int
+}
+
+// currentGoal is the goal of the configuration (Options.Goal, the standard goal by default).
+var currentGoal = StandardGoal(4, 4)
+
+// Caches of the 4x4 goal used by the State solvers, filled by setGoal: the goal row,
+// column and cell of every tile and the corners checked by CornerConflict.
+var (
+	goalRows, goalCols, goalCells [16]int
+	goalCorners                   []cornerTile
+)
+
+// cornerTile is a tile that CornerConflict expects in a corner of the board.
+type cornerTile struct {
+	tile     int
+	row, col int
+}
+
+func init() {
+	setGoal(currentGoal)
+}
+
+// newGoal checks that b is a permutation of its cells and returns it as a goal.
+func newGoal(b Board) (*Goal, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	g := &Goal{Board: b, cells: make([]int, len(b.Tiles))}
+	for cell, tile := range b.Tiles {
+		g.cells[tile] = cell
+	}
+	return g, nil
+}
+
+// StandardGoal returns the goal with the tiles in increasing order and the blank last.
+func StandardGoal(rows, cols int) *Goal {
+	g, _ := newGoal(goalBoard(rows, cols))
+	return g
+}
+
+// GoalLayout returns the goal of a rows x cols board for a layout: standard,
+// blank-first (blank in the first cell), snake (rows alternating direction), spiral
+// (clockwise from the top left corner, the blank last) or a list of tiles.
+func GoalLayout(layout string, rows, cols int) (*Goal, error) {
+	order := make([]int, 0, rows*cols) // cells in the order the tiles 1, 2, ... fill them
+	switch layout {
+	case GoalStandard, "":
+		return StandardGoal(rows, cols), nil
+	case GoalBlankFirst:
+		for cell := 1; cell < rows*cols; cell++ {
+			order = append(order, cell)
+		}
+	case GoalSnake:
+		for row := 0; row < rows; row++ {
+			for k := 0; k < cols; k++ {
+				col := k
+				if row%2 == 1 {
+					col = cols - 1 - k
+				}
+				order = append(order, row*cols+col)
+			}
+		}
+	case GoalSpiral:
+		top, bottom, left, right := 0, rows-1, 0, cols-1
+		for top <= bottom && left <= right {
+			for col := left; col <= right; col++ {
+				order = append(order, top*cols+col)
+			}
+			for row := top + 1; row <= bottom; row++ {
+				order = append(order, row*cols+right)
+			}
+			if top < bottom {
+				for col := right - 1; col >= left; col-- {
+					order = append(order, bottom*cols+col)
+				}
+			}
+			if left < right {
+				for row := bottom - 1; row > top; row-- {
+					order = append(order, row*cols+left)
+				}
+			}
+			top, bottom, left, right = top+1, bottom-1, left+1, right-1
+		}
+	default:
+		b, err := ParseBoard(strings.ReplaceAll(layout, ",", " "), rows, cols)
+		if err != nil {
+			return nil, fmt.Errorf("objetivo inválido: %v (opciones: standard, blank-first, snake, spiral o la lista de fichas)", err)
+		}
+		return newGoal(b)
+	}
+
+	b := Board{Rows: rows, Cols: cols, Tiles: make([]int, rows*cols)}
+	for k, cell := range order[:rows*cols-1] {
+		b.Tiles[cell] = k + 1
+	}
+	return newGoal(b)
+}
+
+// cell, row and col return where tile must end up.
+func (g *Goal) cell(tile int) int { return g.cells[tile] }
+func (g *Goal) row(tile int) int  { return g.cells[tile] / g.Cols }
+func (g *Goal) col(tile int) int  { return g.cells[tile] % g.Cols }
+
+// IsStandard reports whether g is the standard goal of its size.
+func (g *Goal) IsStandard() bool {
+	return equalTiles(g.Tiles, goalBoard(g.Rows, g.Cols).Tiles)
+}
+
+// key identifies the goal in the names of the tables that depend on it: empty for the
+// standard goal, so those files keep their names, and a checksum of the tiles otherwise.
+func (g *Goal) key() string {
+	if g.IsStandard() {
+		return ""
+	}
+	return fmt.Sprintf("goal-%08x", crc32.ChecksumIEEE([]byte(g.String())))
+}
+
+// reachable reports whether the goal can be reached from tiles. The parity rule of
+// isSolvableTiles splits the boards in two classes and every move stays in its class,
+// so tiles reach the goal exactly when both are in the same class.
+func (g *Goal) reachable(tiles []int) bool {
+	return isSolvableTiles(tiles, g.Rows, g.Cols) == isSolvableTiles(g.Tiles, g.Rows, g.Cols)
+}
+
+// goalFor returns currentGoal if it has the given size and the standard goal otherwise.
+func goalFor(rows, cols int) *Goal {
+	if currentGoal.Rows == rows && currentGoal.Cols == cols {
+		return currentGoal
+	}
+	return StandardGoal(rows, cols)
+}
+
+// setGoal makes g the current goal. For a 4x4 goal it also refreshes the caches of the
+// State solvers.
+func setGoal(g *Goal) {
+	currentGoal = g
+	if !g.is4x4() {
+		return
+	}
+	for tile := 0; tile < 16; tile++ {
+		goalRows[tile], goalCols[tile], goalCells[tile] = g.row(tile), g.col(tile), g.cell(tile)
+	}
+	packedGoal = packState(g.State())
+	setWalkingMaps(g.row(0), g.col(0))
+
+	// Each corner expects its goal tile; a corner that holds the blank expects the tile
+	// next to it in its row, as 15 in the standard goal.
+	goalCorners = goalCorners[:0]
+	for _, corner := range [][2]int{{0, 0}, {0, 3}, {3, 0}, {3, 3}} {
+		tile := g.Tiles[corner[0]*4+corner[1]]
+		if tile == 0 {
+			neighbor := 2
+			if corner[1] == 0 {
+				neighbor = 1
+			}
+			tile = g.Tiles[corner[0]*4+neighbor]
+		}
+		goalCorners = append(goalCorners, cornerTile{tile: tile, row: corner[0], col: corner[1]})
+	}
+}
@@ -0,0 +1,52 @@
+package puzzle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGoalLayout(t *testing.T) {
+	tests := []struct {
+		layout     string
+		rows, cols int
+		want       []int
+	}{
+		{GoalStandard, 3, 3, []int{1, 2, 3, 4, 5, 6, 7, 8, 0}},
+		{"", 2, 3, []int{1, 2, 3, 4, 5, 0}},
+		{GoalBlankFirst, 3, 3, []int{0, 1, 2, 3, 4, 5, 6, 7, 8}},
+		{GoalSnake, 3, 3, []int{1, 2, 3, 6, 5, 4, 7, 8, 0}},
+		{GoalSnake, 4, 4, []int{1, 2, 3, 4, 8, 7, 6, 5, 9, 10, 11, 12, 0, 15, 14, 13}},
+		{GoalSpiral, 3, 3, []int{1, 2, 3, 8, 0, 4, 7, 6, 5}},
+		{GoalSpiral, 4, 4, []int{1, 2, 3, 4, 12, 13, 14, 5, 11, 0, 15, 6, 10, 9, 8, 7}},
+		{GoalSpiral, 2, 4, []int{1, 2, 3, 4, 0, 7, 6, 5}},
+		{GoalSpiral, 3, 2, []int{1, 2, 0, 3, 5, 4}},
+		{"8 7 6 5 4 3 2 1 0", 3, 3, []int{8, 7, 6, 5, 4, 3, 2, 1, 0}},
+		{"0,1,2,3", 2, 2, []int{0, 1, 2, 3}},
+	}
+	for _, test := range tests {
+		g, err := GoalLayout(test.layout, test.rows, test.cols)
+		if err != nil {
+			t.Errorf("GoalLayout(%q, %d, %d): %v", test.layout, test.rows, test.cols, err)
+			continue
+		}
+		if !reflect.DeepEqual(g.Tiles, test.want) || g.Rows != test.rows || g.Cols != test.cols {
+			t.Errorf("GoalLayout(%q, %d, %d) = %v, want %v", test.layout, test.rows, test.cols, g.Tiles, test.want)
+		}
+		for cell, tile := range g.Tiles {
+			if g.cell(tile) != cell || g.row(tile) != cell/test.cols || g.col(tile) != cell%test.cols {
+				t.Errorf("GoalLayout(%q, %d, %d): tile %d is not at cell %d", test.layout, test.rows, test.cols, tile, cell)
+			}
+		}
+		if standard := test.layout == GoalStandard || test.layout == ""; g.IsStandard() != standard {
+			t.Errorf("GoalLayout(%q, %d, %d).IsStandard() = %v", test.layout, test.rows, test.cols, !standard)
+		}
+	}
+}
+
+func TestGoalLayoutErrors(t *testing.T) {
+	for _, layout := range []string{"diagonal", "1 2 3", "1 1 2 3 4 5 6 7 8", "1 2 3 4 5 6 7 8 9"} {
+		if _, err := GoalLayout(layout, 3, 3); err == nil {
+			t.Errorf("GoalLayout(%q, 3, 3): no error", layout)
+		}
+	}
+}
@@ -0,0 +1,507 @@
+package puzzle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// walkingTable holds the dense walking distance entries, indexed by walkingIndex.
+	walkingTable []byte
+	// loadStatesOnce ensures that walkingTable is loaded only once.
+	loadStatesOnce sync.Once
+	// loadStatesErr is the error of the first load, returned to every later caller.
+	loadStatesErr error
+
+	// wdRowMap and wdColMap renumber the rows and the columns so that the goal row (column)
+	// of the blank is the last one, as in the walking distance table; turning the board
+	// upside down does not change the walking distance. walkingRows and walkingCols are
+	// false when the blank must end in a middle row (column): that half of the walking
+	// distance then uses wdRowTable (wdColTable) and counts 0 if it is not loaded.
+	wdRowMap, wdColMap       [4]int
+	walkingRows, walkingCols bool
+)
+
+// setWalkingMaps sets wdRowMap, wdColMap, walkingRows and walkingCols for a goal with the
+// blank in the given row and column.
+func setWalkingMaps(blankRow, blankCol int) {
+	wdRowMap, walkingRows = walkingMap(blankRow)
+	wdColMap, walkingCols = walkingMap(blankCol)
+}
+
+// walkingMap returns the renumbering of the lines of one axis when the blank must end in line.
+func walkingMap(line int) ([4]int, bool) {
+	switch line {
+	case 3:
+		return [4]int{0, 1, 2, 3}, true
+	case 0:
+		return [4]int{3, 2, 1, 0}, true
+	}
+	return [4]int{0, 1, 2, 3}, false
+}
+
+// loadWalkingTable loads the walking distance table once for the current Tables (see
+// resetTables). It uses the binary table, or converts the JSON table if only that one
+// exists, or falls back to Tables.Fallback (the copy embedded in the binary).
+func loadWalkingTable() error {
+	loadStatesOnce.Do(func() {
+		fileName := tables.path(walkingDistanceFile)
+		data, err := tables.read(walkingDistanceFile)
+		if errors.Is(err, fs.ErrNotExist) {
+			if tables.has(walkingDistanceJSON) {
+				walkingTable, loadStatesErr = readWalkingJSON(walkingDistanceJSON)
+				return
+			}
+			if tables.hasFallback(walkingDistanceFile) {
+				fileName = "embedded table"
+				data, err = tables.readAny(walkingDistanceFile)
+			}
+		}
+		if err != nil {
+			loadStatesErr = fmt.Errorf("error reading file: %v", err)
+			return
+		}
+		walkingTable, err = decodeTable(data, tableWalkingDistance, 4, 4, walkingEntries)
+		if err != nil {
+			loadStatesErr = fmt.Errorf("%s: %v", fileName, err)
+		}
+	})
+	return loadStatesErr
+}
+
+// readWalkingJSON reads a walking distance table of Tables in the JSON format and returns
+// its dense entries.
+func readWalkingJSON(name string) ([]byte, error) {
+	data, err := tables.read(name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %v", err)
+	}
+	entries, err := decodeWalkingJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", tables.path(name), err)
+	}
+	return entries, nil
+}
+
+// decodeWalkingJSON converts a walking distance table in the JSON format into its dense entries.
+func decodeWalkingJSON(data []byte) ([]byte, error) {
+	var states map[string]int
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("error decoding JSON: %v", err)
+	}
+	return walkingTableFromJSON(states)
+}
+
+// Calcula la heurística Manhattan Distance para un 15-puzzle, respecto del objetivo actual
+func ManhattanDistance(state [4][4]int) int {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return manhattanDistance(state)
+}
+
+// manhattanDistance is ManhattanDistance without the lock.
+func manhattanDistance(state [4][4]int) int {
+	distance := 0
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			val := state[i][j]
+			if val != 0 {
+				goalX, goalY := goalRow(val), goalCol(val)
+				distance += int(math.Abs(float64(i-goalX)) + math.Abs(float64(j-goalY)))
+			}
+		}
+	}
+	return distance
+}
+
+// Linear Conflict Heuristic
+func LinearConflict(state [4][4]int) int {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return linearConflict(state)
+}
+
+// linearConflict is LinearConflict without the lock.
+func linearConflict(state [4][4]int) int {
+	conflict := 0
+
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			tile := state[i][j]
+
+			// Verificar conflicto en la fila
+			if tile != 0 && goalRow(tile) == i {
+				for k := j + 1; k < 4; k++ {
+					tile2 := state[i][k]
+					if tile2 != 0 && goalRow(tile2) == i && goalCol(tile) > goalCol(tile2) {
+						// fmt.Printf("Conflict Row: %d > %d\n", tile, tile2)
+						conflict += 2
+					}
+				}
+			}
+
+			// Verificar conflicto en la columna
+			tile = state[j][i]
+			if tile != 0 && goalCol(tile) == i {
+				for k := j + 1; k < 4; k++ {
+					tile2 := state[k][i]
+					if tile2 != 0 && goalCol(tile2) == i && goalRow(tile) > goalRow(tile2) {
+						// fmt.Printf("Conflict Column: %d > %d\n", tile, tile2)
+						conflict += 2
+					}
+				}
+			}
+		}
+	}
+
+	return conflict
+}
+
+// lineConflict calcula el conflicto lineal admisible de una sola fila o columna.
+// Recibe, en orden de posición, la posición objetivo dentro de la línea de cada ficha
+// que ya está en su línea objetivo. Las fichas que pueden quedarse en la línea son las de
+// la subsecuencia creciente más larga; cada una de las demás tiene que salir y volver,
+// así que suma 2 movimientos por ficha: 2 * (len(goals) - LIS).
+func lineConflict(goals []int) int {
+	// tails[k] es el menor final de una subsecuencia creciente de largo k+1.
+	tails := make([]int, 0, len(goals))
+	for _, goal := range goals {
+		k := 0
+		for k < len(tails) && tails[k] < goal {
+			k++
+		}
+		if k == len(tails) {
+			tails = append(tails, goal)
+		} else {
+			tails[k] = goal
+		}
+	}
+	return 2 * (len(goals) - len(tails))
+}
+
+// AdmissibleLinearConflict is the admissible variant of LinearConflict: instead of adding 2
+// for every reversed pair it adds 2 for each tile that must leave its line, so it never
+// overestimates when combined with the Manhattan Distance.
+func AdmissibleLinearConflict(state [4][4]int) int {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return admissibleLinearConflict(state)
+}
+
+// admissibleLinearConflict is AdmissibleLinearConflict without the lock.
+func admissibleLinearConflict(state [4][4]int) int {
+	conflict := 0
+	for line := 0; line < 4; line++ {
+		var rowGoals, colGoals []int
+		for k := 0; k < 4; k++ {
+			if tile := state[line][k]; tile != 0 && goalRow(tile) == line {
+				rowGoals = append(rowGoals, goalCol(tile))
+			}
+			if tile := state[k][line]; tile != 0 && goalCol(tile) == line {
+				colGoals = append(colGoals, goalRow(tile))
+			}
+		}
+		conflict += lineConflict(rowGoals) + lineConflict(colGoals)
+	}
+	return conflict
+}
+
+// matrixToKey converts a 2D matrix into a string key by flattening and joining elements with commas.
+// Parameters:
+// - matrix: The 2D integer matrix to convert.
+// Returns:
+// - A string representing the flattened matrix.
+func matrixToKey(matrix [][]int) string {
+	parts := make([]string, 0, 16)
+	for _, row := range matrix {
+		for _, num := range row {
+			parts = append(parts, strconv.Itoa(num))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// createHorizontalDistanceMapping generates a mapping of numbers to their horizontal distance groups.
+// Returns:
+// - A map where keys are numbers 1-15 and values are their goal rows, renumbered with wdRowMap.
+func createHorizontalDistanceMapping() map[int]int {
+	mapping := make(map[int]int)
+	for tile := 1; tile < 16; tile++ {
+		mapping[tile] = wdRowMap[goalRow(tile)]
+	}
+	return mapping
+}
+
+// createVerticalDistanceMapping generates a mapping of numbers to their vertical distance groups.
+// Returns:
+// - A map where keys are numbers 1-15 and values are their goal columns, renumbered with wdColMap.
+func createVerticalDistanceMapping() map[int]int {
+	mapping := make(map[int]int)
+	for tile := 1; tile < 16; tile++ {
+		mapping[tile] = wdColMap[goalCol(tile)]
+	}
+	return mapping
+}
+
+// getMatrixValue retrieves a precomputed value from the walking distance table based on the matrix state.
+// Parameters:
+// - matrix: The 2D matrix to look up.
+// Returns:
+// - The associated integer value or error if not found.
+func getMatrixValue(matrix [][]int) (int, error) {
+	if err := loadWalkingTable(); err != nil {
+		return -1, err
+	}
+
+	if index, ok := validWalkingIndex(packCounts(matrix)); ok && walkingTable[index] != tableUnreachable {
+		return int(walkingTable[index]), nil
+	}
+
+	return -1, fmt.Errorf("key '%s' not found", matrixToKey(matrix))
+}
+
+// transposeMatrix swaps rows and columns of a matrix.
+// Parameters:
+// - matrix: The input matrix to transpose.
+// Returns:
+// - A new transposed matrix.
+func transposeMatrix(matrix [4][4]int) [][]int {
+	rows := len(matrix)
+	cols := len(matrix[0])
+	transposed := make([][]int, cols)
+	for i := range transposed {
+		transposed[i] = make([]int, rows)
+	}
+
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			transposed[j][i] = matrix[i][j]
+		}
+	}
+
+	return transposed
+}
+
+// walkingDistance calculates Walking distance relative to the current goal.
+// Returns:
+// - The walking distance.
+func walkingDistance(matrix [4][4]int) int {
+	total := 0
+
+	transposedMatrix := transposeMatrix(matrix)
+
+	verticalBase := make([][]int, 4)
+	horizontalBase := make([][]int, 4)
+	for i := range verticalBase {
+		verticalBase[i] = make([]int, 4)
+		horizontalBase[i] = make([]int, 4)
+	}
+
+	verticalMapping := createVerticalDistanceMapping()
+	horizontalMapping := createHorizontalDistanceMapping()
+
+	// Calculate horizontal metrics
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			if matrix[i][j] == 0 {
+				continue
+			}
+			index := horizontalMapping[matrix[i][j]]
+			horizontalBase[wdRowMap[i]][index]++
+		}
+	}
+
+	// Calculate vertical metrics
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			if transposedMatrix[i][j] == 0 {
+				continue
+			}
+			index := verticalMapping[transposedMatrix[i][j]]
+			verticalBase[wdColMap[i]][index]++
+		}
+	}
+
+	// A half whose blank does not end in the first or last line uses its own table (see walkingMap).
+	verticalValue, horizontalValue := 0, 0
+	var err1, err2 error
+	if walkingCols {
+		verticalValue, err1 = getMatrixValue(verticalBase)
+	} else if wdColTable != nil {
+		verticalValue = wdColTable.lookupMatrix(verticalBase)
+	}
+	if walkingRows {
+		horizontalValue, err2 = getMatrixValue(horizontalBase)
+	} else if wdRowTable != nil {
+		horizontalValue = wdRowTable.lookupMatrix(horizontalBase)
+	}
+
+	if err1 == nil && err2 == nil {
+		total = verticalValue + horizontalValue
+	} else {
+		logf("Error calculating MV: %v %v\n", err1, err2)
+	}
+
+	return total
+}
+
+// Corner Conflict heuristic
+func CornerConflict(state [4][4]int) int {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return cornerConflict(state)
+}
+
+// cornerConflict is CornerConflict without the lock.
+func cornerConflict(state [4][4]int) int {
+	conflict := 0
+	// Goal corner positions (see setGoal); with the standard goal:
+	// {1 -> (0,0), 4 -> (0,3), 13 -> (3,0), 15 -> (3,3)}
+	for _, corner := range goalCorners {
+		tile := corner.tile
+		for i := 0; i < 4; i++ {
+			for j := 0; j < 4; j++ {
+				if state[i][j] == tile {
+					// If tile is not in its corner position, check for conflicts
+					if i != corner.row || j != corner.col {
+						// If the tile is blocked by another tile, add penalty
+						if (i == 0 && state[i+1][j] != 0) || (j == 0 && state[i][j+1] != 0) ||
+							(i == 3 && state[i-1][j] != 0) || (j == 3 && state[i][j-1] != 0) {
+							conflict += 2
+						}
+					}
+				}
+			}
+		}
+	}
+	return conflict
+}
+
+// heuristicCalculus calculates the heuristic value for a given puzzle state by combining
+// multiple heuristic metrics: Manhattan Distance, Linear Conflict, and Walking Distance.
+// Parameters:
+//   - matrix: The current state of the puzzle as a 2D integer matrix.
+//   - testHeuristic: Whether to add Corner Conflict / 2.
+//
+// Returns:
+//   - The total heuristic value as an integer.
+//
+// HeuristicReport returns the individual values.
+func HeuristicCalculus(matrix [4][4]int, testHeuristic bool) int {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return heuristicCalculus(matrix, testHeuristic)
+}
+
+// heuristicCalculus is HeuristicCalculus without the lock.
+func heuristicCalculus(matrix [4][4]int, testHeuristic bool) int {
+	// Calculate the Manhattan Distance heuristic, which sums the distances of each tile
+	// from its goal position.
+	manhattanDistanceValue := manhattanDistance(matrix)
+
+	// Calculate the Linear Conflict heuristic, which counts pairs of tiles in the same row
+	// or column that are in their correct line but reversed, adding 2 for each conflict.
+	linearConflictValue := linearConflict(matrix)
+
+	// Calculate the Walking Distance heuristic, which estimates the minimum number of moves
+	// required to solve the puzzle based on the positions of tiles relative to their goals.
+	walkingDistanceValue := walkingDistance(matrix)
+
+	if testHeuristic {
+		cornerConflictValue := cornerConflict(matrix)
+
+		// Combine the four heuristic values to get the total heuristic estimate.
+		heuristicValue := (manhattanDistanceValue / 3) + linearConflictValue + walkingDistanceValue + (cornerConflictValue / 2)
+
+		// Return the total heuristic value.
+		return heuristicValue
+	}
+	// Combine the three heuristic values to get the total heuristic estimate.
+	heuristicValue := (manhattanDistanceValue / 3) + linearConflictValue + walkingDistanceValue
+
+	// Return the total heuristic value.
+	return heuristicValue
+
+}
+
+// AdmissibleHeuristicCalculus calculates a heuristic value that never overestimates the
+// number of moves left, so IDA* returns optimal solutions with it. Manhattan Distance plus
+// the admissible Linear Conflict and the Walking Distance are both admissible, but they
+// count the same moves, so they are combined with max instead of being added.
+// Parameters:
+//   - matrix: The current state of the puzzle as a 2D integer matrix.
+//
+// Returns:
+//   - The admissible heuristic value as an integer.
+func AdmissibleHeuristicCalculus(matrix [4][4]int) int {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return admissibleHeuristicCalculus(matrix)
+}
+
+// admissibleHeuristicCalculus is AdmissibleHeuristicCalculus without the lock.
+func admissibleHeuristicCalculus(matrix [4][4]int) int {
+	manhattanDistanceValue := manhattanDistance(matrix)
+	linearConflictValue := admissibleLinearConflict(matrix)
+	walkingDistanceValue := walkingDistance(matrix)
+
+	heuristicValue := manhattanDistanceValue + linearConflictValue
+	if walkingDistanceValue > heuristicValue {
+		heuristicValue = walkingDistanceValue
+	}
+	return heuristicValue
+}
+
+// HeuristicReport holds the components of the heuristic value of a state for the
+// selected mode, so they can be reported without parsing the text of HeuristicCalculus.
+// Components that the mode does not use are nil.
+type HeuristicReport struct {
+	Formula         string `json:"formula"`
+	Manhattan       *int   `json:"manhattan,omitempty"`
+	LinearConflict  *int   `json:"linear_conflict,omitempty"`
+	WalkingDistance *int   `json:"walking_distance,omitempty"`
+	TrueDistance    *int   `json:"true_distance,omitempty"`
+	CornerConflict  *int   `json:"corner_conflict,omitempty"`
+	PatternDatabase *int   `json:"pattern_database,omitempty"`
+	Total           int    `json:"total"`
+}
+
+// heuristicReport computes the heuristic components of a state for the selected mode.
+func heuristicReport(matrix [4][4]int) HeuristicReport {
+	if pdbPartition != "" {
+		value := patternDatabaseValue(matrix)
+		return HeuristicReport{
+			Formula:         "pattern databases " + pdbPartition,
+			PatternDatabase: &value,
+			Total:           value,
+		}
+	}
+
+	manhattanDistanceValue := manhattanDistance(matrix)
+	walkingDistanceValue := walkingDistance(matrix)
+	report := HeuristicReport{Manhattan: &manhattanDistanceValue, WalkingDistance: &walkingDistanceValue}
+	if optimalMode {
+		linearConflictValue := admissibleLinearConflict(matrix)
+		report.Formula = "max(walkingDistanceValue, manhattanDistanceValue + linearConflictValue)"
+		report.LinearConflict = &linearConflictValue
+		report.Total = admissibleHeuristicCalculus(matrix)
+		return report
+	}
+
+	linearConflictValue := linearConflict(matrix)
+	report.LinearConflict = &linearConflictValue
+	report.Formula = "(manhattanDistanceValue / 3) + linearConflictValue + walkingDistanceValue"
+	if extraHeuristic {
+		cornerConflictValue := cornerConflict(matrix)
+		report.CornerConflict = &cornerConflictValue
+		report.Formula += " + (cornerConflictValue / 2)"
+	}
+	report.Total = heuristicCalculus(matrix, extraHeuristic)
+	return report
+}
@@ -0,0 +1,103 @@
+package puzzle
+
+import "testing"
+
+// bruteLineConflict is 2 * the fewest tiles that must leave the line so that no two of
+// the remaining ones are reversed, trying every set of tiles.
+func bruteLineConflict(goals []int) int {
+	best := len(goals)
+	for removed := 0; removed < 1<<len(goals); removed++ {
+		count, ok := 0, true
+		for a := range goals {
+			if removed&(1<<a) != 0 {
+				count++
+				continue
+			}
+			for b := a + 1; b < len(goals); b++ {
+				if removed&(1<<b) == 0 && goals[a] > goals[b] {
+					ok = false
+				}
+			}
+		}
+		if ok && count < best {
+			best = count
+		}
+	}
+	return 2 * best
+}
+
+// permutations calls visit with every permutation of 0..n-1.
+func permutations(n int, visit func([]int)) {
+	var walk func(p []int, k int)
+	walk = func(p []int, k int) {
+		if k == len(p) {
+			visit(p)
+			return
+		}
+		for i := k; i < len(p); i++ {
+			p[k], p[i] = p[i], p[k]
+			walk(p, k+1)
+			p[k], p[i] = p[i], p[k]
+		}
+	}
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+	walk(p, 0)
+}
+
+func TestLineConflict(t *testing.T) {
+	tests := []struct {
+		goals []int
+		want  int
+	}{
+		{nil, 0},
+		{[]int{0, 1, 2, 3}, 0},
+		{[]int{1, 0}, 2},
+		{[]int{3, 2, 1, 0}, 6},
+		{[]int{1, 3, 0, 4, 2}, 4},
+		{[]int{2, 0, 3}, 2},
+	}
+	for _, test := range tests {
+		if got := lineConflict(test.goals); got != test.want {
+			t.Errorf("lineConflict(%v) = %d, want %d", test.goals, got, test.want)
+		}
+	}
+}
+
+func TestLineConflictBruteForce(t *testing.T) {
+	for n := 0; n <= 6; n++ {
+		permutations(n, func(goals []int) {
+			if got, want := lineConflict(goals), bruteLineConflict(goals); got != want {
+				t.Errorf("lineConflict(%v) = %d, want %d", goals, got, want)
+			}
+		})
+	}
+}
+
+// TestAdmissibleLinearConflictBFS checks that the Manhattan Distance plus the admissible
+// Linear Conflict never exceeds the real distance of the states near the goal, found
+// with a breadth-first search.
+func TestAdmissibleLinearConflictBFS(t *testing.T) {
+	const depth = 14
+	goal := packState(StandardGoal(4, 4).State())
+	seen := map[PackedState]bool{goal: true}
+	frontier := []PackedState{goal}
+	for distance := 0; distance <= depth; distance++ {
+		var next []PackedState
+		for _, p := range frontier {
+			state := p.unpack()
+			if h := ManhattanDistance(state) + AdmissibleLinearConflict(state); h > distance {
+				t.Fatalf("%v: heuristic %d, distance %d", state, h, distance)
+			}
+			for m := Up; m <= Right; m++ {
+				if q, _, _, ok := p.slide(m); ok && !seen[q] {
+					seen[q] = true
+					next = append(next, q)
+				}
+			}
+		}
+		frontier = next
+	}
+}
@@ -0,0 +1,298 @@
+package puzzle
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+var generatedStates int
+
+// IterationInfo describe una iteración de IDA*: el límite usado, el siguiente límite
+// y los estados generados acumulados al terminarla.
+type IterationInfo struct {
+	Bound     int `json:"bound"`
+	NextBound int `json:"next_bound"`
+	Generated int `json:"generated"`
+}
+
+// iterations guarda las iteraciones de la última búsqueda IDA*.
+var iterations []IterationInfo
+
+// expandedStates cuenta los estados cuyos sucesores se generaron en la última búsqueda.
+var expandedStates int
+
+// ttStats guarda las estadísticas de la tabla de transposición de la última búsqueda.
+var ttStats transpositionStats
+
+// Representamos el estado como una matriz 4x4
+type State [4][4]int
+
+// Heurística combinada: Manhattan + Linear Conflict
+// Se asume que la función HeuristicCalculus está definida en otro lado.
+// Calcula todo desde cero; durante la búsqueda se usa heuristicComponents, que se
+// actualiza en cada movimiento.
+// En modo óptimo se usa únicamente la combinación admisible.
+func heuristic(state State) int {
+	if pdbPartition != "" {
+		return patternDatabaseValue(state)
+	}
+	if optimalMode {
+		return admissibleHeuristicCalculus(state)
+	}
+	return heuristicCalculus(state, extraHeuristic)
+}
+
+// solutionQuality indica si la longitud de la solución está garantizada como óptima,
+// lo cual solo ocurre cuando la heurística usada es admisible.
+func solutionQuality() string {
+	if heuristicAdmissible() {
+		return "proven optimal"
+	}
+	return "heuristic, possibly suboptimal"
+}
+
+// Verifica si el estado es el objetivo: por defecto 1,2,3,...,15 y 0 en la esquina
+// inferior derecha, o el elegido con Options.Goal
+func isGoal(state State) bool {
+	return packState(state) == packedGoal
+}
+
+// Definición de movimientos
+type Move int
+
+const (
+	Up Move = iota
+	Down
+	Left
+	Right
+)
+
+// moveLetters son las letras de cada movimiento en la notación compacta.
+var moveLetters = [4]byte{Up: 'U', Down: 'D', Left: 'L', Right: 'R'}
+
+// String devuelve el nombre del movimiento.
+func (m Move) String() string {
+	switch m {
+	case Up:
+		return "Up"
+	case Down:
+		return "Down"
+	case Left:
+		return "Left"
+	case Right:
+		return "Right"
+	}
+	return "Invalid"
+}
+
+// Función para obtener el movimiento opuesto (para evitar retrocesos)
+func opposite(m Move) Move {
+	switch m {
+	case Up:
+		return Down
+	case Down:
+		return Up
+	case Left:
+		return Right
+	case Right:
+		return Left
+	}
+	return -1
+}
+
+// Offsets de cada movimiento: Up, Down, Left, Right
+var moveOffsets = [4][2]int{
+	Up:    {-1, 0},
+	Down:  {1, 0},
+	Left:  {0, -1},
+	Right: {0, 1},
+}
+
+// Encuentra la posición del espacio vacío (0)
+func findBlank(state State) (int, int) {
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			if state[i][j] == 0 {
+				return i, j
+			}
+		}
+	}
+	return -1, -1 // No debería ocurrir
+}
+
+// Realiza un movimiento sobre el estado; retorna el nuevo estado y si el movimiento es válido
+func move(state State, m Move) (State, bool) {
+	i, j := findBlank(state)
+	di := moveOffsets[m][0]
+	dj := moveOffsets[m][1]
+	newI := i + di
+	newJ := j + dj
+	if newI < 0 || newI >= 4 || newJ < 0 || newJ >= 4 {
+		return state, false
+	}
+	newState := state
+	// Intercambiar el espacio vacío con la ficha adyacente
+	newState[i][j], newState[newI][newJ] = newState[newI][newJ], newState[i][j]
+	return newState, true
+}
+
+// searcher guarda el estado de una búsqueda en profundidad: el contador de estados generados,
+// la tabla de transposición opcional, los límites de tiempo y nodos, el mejor camino parcial
+// y, en la versión paralela, la tarea que recorre y el índice de la primera tarea con solución.
+type searcher struct {
+	generated int
+	expanded  int
+	task      int64
+	found     *int64
+	table     *transpositionTable
+	limits    *searchLimits
+	// reported es la parte de generated ya sumada a limits.
+	reported int
+	best     partialPath
+}
+
+// cancelled indica si otra tarea anterior ya encontró una solución, en cuyo caso
+// la rama actual no puede aportar la solución que daría el solver secuencial, o si
+// la búsqueda se detuvo por tiempo, nodos o cancelación.
+func (s *searcher) cancelled() bool {
+	return (s.found != nil && atomic.LoadInt64(s.found) < s.task) || (s.limits != nil && s.limits.stopped())
+}
+
+// Función recursiva de búsqueda (IDA*) que retorna:
+// - un flag de solución encontrada,
+// - un nuevo límite si no se encontró solución,
+// - y el camino (slice de estados) en caso de éxito.
+// Trabaja sobre PackedState para reducir la memoria copiada en cada nodo.
+func (s *searcher) search(state PackedState, g int, bound int, prevMove *Move, statePath []PackedState, h heuristicComponents) (bool, int, []PackedState) {
+	if s.cancelled() {
+		return false, math.MaxInt32, nil
+	}
+	hValue := h.value(state)
+	s.best.offer(hValue, statePath)
+	f := g + hValue
+	if f > bound {
+		return false, f, nil
+	}
+	if state.isGoal() {
+		return true, bound, statePath
+	}
+	// Si el estado ya se expandió en esta iteración con un g menor o igual, su subárbol ya fue recorrido
+	if s.table != nil && s.table.visit(state, g, bound) {
+		return false, math.MaxInt32, nil
+	}
+	s.expanded++
+	minBound := math.MaxInt32
+	// Probar movimientos legales en orden: Up, Down, Left, Right
+	for _, m := range legalMoves[state.blank] {
+		// Evitar el movimiento inverso al último
+		if prevMove != nil && m == opposite(*prevMove) {
+			continue
+		}
+		newState, tile, from, _ := state.slide(m)
+		if s.limits != nil && s.limits.exceeded(s.generated, &s.reported) {
+			return false, math.MaxInt32, nil
+		}
+		s.generated++ // Contamos el nuevo estado generado
+		newStatePath := append(statePath, newState)
+		solved, t, resultPath := s.search(newState, g+1, bound, &m, newStatePath, h.update(newState, tile, from, int(state.blank)))
+		if solved {
+			return true, t, resultPath
+		}
+		if t < minBound {
+			minBound = t
+		}
+	}
+	return false, minBound, nil
+}
+
+// idaOutcome es el resultado de idaStar y parallelIDAStar: el camino si se encontró
+// solución, el último límite usado y el mejor camino parcial visto.
+type idaOutcome struct {
+	path   []State
+	solved bool
+	bound  int
+	best   partialPath
+}
+
+// Función principal del solver: ejecuta IDA* iterativamente hasta encontrar una solución,
+// agotar los estados o alcanzar los límites de limits.
+func idaStar(root State, limits *searchLimits) idaOutcome {
+	start := time.Now()
+	bound := heuristic(root)
+	packedRoot := packState(root)
+	initialPath := []PackedState{packedRoot}
+	s := &searcher{limits: limits}
+	if ttMegabytes > 0 {
+		s.table = newTranspositionTable(ttMegabytes, ttPolicy)
+	}
+	for {
+		previous := s.generated
+		solved, newBound, path := s.search(packedRoot, 0, bound, nil, initialPath, newHeuristicComponents(packedRoot))
+		generatedStates = s.generated
+		expandedStates = s.expanded
+		if s.table != nil {
+			ttStats = s.table.stats
+		}
+		if limits.stopped() {
+			return idaOutcome{bound: bound, best: s.best}
+		}
+		reportIteration(start, bound, newBound, previous, generatedStates)
+		if solved {
+			return idaOutcome{path: unpackPath(path), solved: true, bound: bound, best: s.best}
+		}
+		if newBound == math.MaxInt32 {
+			return idaOutcome{bound: bound, best: s.best}
+		}
+		bound = newBound
+	}
+}
+
+// SolverIDAStar ejecuta el solver IDA*, secuencial o paralelo, y devuelve la secuencia de estados.
+// Si ctx termina o se generan maxNodes estados, devuelve el último límite y el mejor camino parcial.
+// Si no se pueden cargar las tablas de la heurística no busca y lo indica en Stopped.
+func SolverIDAStar(ctx context.Context, initial State) Result {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return solverIDAStar(ctx, initial)
+}
+
+// solverIDAStar is SolverIDAStar without the lock.
+func solverIDAStar(ctx context.Context, initial State) Result {
+	if err := loadHeuristicTables(); err != nil {
+		return Result{Stopped: err.Error()}
+	}
+	generatedStates = 0
+	expandedStates = 0
+	iterations = nil
+	ttStats = transpositionStats{}
+	limits := newSearchLimits(ctx)
+	var outcome idaOutcome
+	if workers > 1 {
+		outcome = parallelIDAStar(initial, workers, limits)
+	} else {
+		outcome = idaStar(initial, limits)
+	}
+	if ttMegabytes > 0 {
+		logTranspositionStats(ttStats)
+	}
+	result := Result{
+		Solved:     outcome.solved,
+		Path:       outcome.path,
+		Generated:  generatedStates,
+		Expanded:   expandedStates,
+		Iterations: iterations,
+		Bound:      outcome.bound,
+	}
+	if outcome.solved {
+		result.Quality = solutionQuality()
+	} else {
+		result.Stopped = "se agotaron los estados"
+		if limits.stopped() {
+			result.Stopped = limits.reason
+		}
+		result.setPartial(outcome.best)
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+package puzzle
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// idaInstances are 4x4 boards with their optimal lengths.
+var idaInstances = []struct {
+	board  string
+	length int
+}{
+	{"5 1 2 4 9 6 3 8 13 10 7 11 14 0 15 12", 10},
+	{"5 4 8 3 14 9 6 11 13 1 0 12 2 10 15 7", 38},
+	{"9 3 1 10 2 7 4 0 5 15 8 6 13 14 11 12", 38},
+	{"1 2 15 5 12 6 3 4 7 13 8 14 9 0 11 10", 40},
+	{"1 6 8 2 11 15 7 3 14 13 9 12 5 4 0 10", 39},
+}
+
+func TestIDAStarVariants(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"sequential", Options{}},
+		{"parallel 2", Options{Workers: 2}},
+		{"parallel 4", Options{Workers: 4}},
+		{"transposition table", Options{TTMegabytes: 4}},
+		{"transposition table always", Options{TTMegabytes: 4, TTPolicy: "always"}},
+		{"parallel with transposition table", Options{Workers: 4, TTMegabytes: 4}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := test.opts
+			opts.Heuristic = HeuristicAdmissible
+			for _, instance := range idaInstances {
+				b, err := ParseBoard(instance.board, 4, 4)
+				if err != nil {
+					t.Fatal(err)
+				}
+				result, err := Solve(context.Background(), b, opts)
+				if err != nil {
+					t.Fatalf("%s: %v", instance.board, err)
+				}
+				if !result.Solved || result.Length != instance.length {
+					t.Errorf("%s: solved %v in %d moves, want %d", instance.board, result.Solved, result.Length, instance.length)
+				}
+				if _, err := BoardMoves(result.BoardPath); err != nil {
+					t.Errorf("%s: invalid path: %v", instance.board, err)
+				} else if !result.BoardPath[len(result.BoardPath)-1].IsGoal() {
+					t.Errorf("%s: the path does not end at the goal", instance.board)
+				}
+			}
+		})
+	}
+}
+
+// TestParallelCountsEveryTask checks that the nodes of the parallel search include those of
+// the tasks cancelled after the first solution: with a node budget every node generated by
+// a worker is added to the shared count of the limits, so both totals must agree.
+func TestParallelCountsEveryTask(t *testing.T) {
+	if err := Configure(Options{Heuristic: HeuristicAdmissible}); err != nil {
+		t.Fatal(err)
+	}
+	if err := PrepareTables(4, 4); err != nil {
+		t.Fatal(err)
+	}
+	for _, instance := range idaInstances {
+		b, err := ParseBoard(instance.board, 4, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		limits := &searchLimits{ctx: context.Background(), maxNodes: math.MaxInt64}
+		if outcome := parallelIDAStar(b.State(), 4, limits); !outcome.solved {
+			t.Fatalf("%s: not solved", instance.board)
+		}
+		if int64(generatedStates) != limits.nodes {
+			t.Errorf("%s: generated %d nodes, the workers reported %d", instance.board, generatedStates, limits.nodes)
+		}
+	}
+}
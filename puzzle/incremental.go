@@ -0,0 +1,200 @@
+package puzzle
+
+// countShift returns the bit offset of the cell (row, group) of a packed count matrix.
+// Each cell holds a count between 0 and 4, so it fits in 3 bits.
+func countShift(row, group int) uint {
+	return uint(3 * (row*4 + group))
+}
+
+// heuristicComponents carries the pieces of the heuristic of a state along the search path,
+// so that each move only updates what the moved tile changes instead of rescanning the board.
+type heuristicComponents struct {
+	manhattan    int
+	rowConflicts [4]int
+	colConflicts [4]int
+	// wdRows counts, for each row, how many tiles belong to each goal row; wdCols does
+	// the same for columns. Both are packed as in countShift.
+	wdRows, wdCols uint64
+	pdb            [maxPatterns]int
+	usePDB         bool
+}
+
+// goalRow and goalCol return where a tile must end up in the goal state (see setGoal).
+func goalRow(tile int) int { return goalRows[tile] }
+func goalCol(tile int) int { return goalCols[tile] }
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// rowConflict returns the linear conflict of row i, using the admissible variant in optimal mode.
+func rowConflict(state PackedState, i int) int {
+	if optimalMode {
+		var goals []int
+		for k := 0; k < 4; k++ {
+			if tile := state.tile(i*4 + k); tile != 0 && goalRow(tile) == i {
+				goals = append(goals, goalCol(tile))
+			}
+		}
+		return lineConflict(goals)
+	}
+	conflict := 0
+	for j := 0; j < 4; j++ {
+		tile := state.tile(i*4 + j)
+		if tile == 0 || goalRow(tile) != i {
+			continue
+		}
+		for k := j + 1; k < 4; k++ {
+			tile2 := state.tile(i*4 + k)
+			if tile2 != 0 && goalRow(tile2) == i && goalCol(tile) > goalCol(tile2) {
+				conflict += 2
+			}
+		}
+	}
+	return conflict
+}
+
+// colConflict returns the linear conflict of column j, using the admissible variant in optimal mode.
+func colConflict(state PackedState, j int) int {
+	if optimalMode {
+		var goals []int
+		for k := 0; k < 4; k++ {
+			if tile := state.tile(k*4 + j); tile != 0 && goalCol(tile) == j {
+				goals = append(goals, goalRow(tile))
+			}
+		}
+		return lineConflict(goals)
+	}
+	conflict := 0
+	for i := 0; i < 4; i++ {
+		tile := state.tile(i*4 + j)
+		if tile == 0 || goalCol(tile) != j {
+			continue
+		}
+		for k := i + 1; k < 4; k++ {
+			tile2 := state.tile(k*4 + j)
+			if tile2 != 0 && goalCol(tile2) == j && goalRow(tile) > goalRow(tile2) {
+				conflict += 2
+			}
+		}
+	}
+	return conflict
+}
+
+// newHeuristicComponents computes every component of a state from scratch.
+func newHeuristicComponents(state PackedState) heuristicComponents {
+	var h heuristicComponents
+	if pdbPartition != "" {
+		h.usePDB = true
+		for p := range patternCache {
+			h.pdb[p] = patternValue(state, p)
+		}
+	}
+
+	for cell := 0; cell < 16; cell++ {
+		tile := state.tile(cell)
+		if tile == 0 {
+			continue
+		}
+		i, j := cell/4, cell%4
+		h.manhattan += abs(i-goalRow(tile)) + abs(j-goalCol(tile))
+		h.wdRows += 1 << countShift(wdRowMap[i], wdRowMap[goalRow(tile)])
+		h.wdCols += 1 << countShift(wdColMap[j], wdColMap[goalCol(tile)])
+	}
+	for i := 0; i < 4; i++ {
+		h.rowConflicts[i] = rowConflict(state, i)
+		h.colConflicts[i] = colConflict(state, i)
+	}
+	return h
+}
+
+// patternValue looks up the cost of pattern p of patternCache for a state.
+func patternValue(state PackedState, p int) int {
+	pdb := patternCache[p]
+	var buffer [16]int
+	positions := buffer[:len(pdb.Tiles)]
+	for cell := 0; cell < 16; cell++ {
+		if tile := state.tile(cell); tile != 0 && patternOfTile[tile][0] == p {
+			positions[patternOfTile[tile][1]] = cell
+		}
+	}
+	return int(pdb.Table[patternRank(positions)])
+}
+
+// update returns the components of newState, where tile slid from cell from into cell to
+// (the previous blank). Only the moved tile, the two rows or columns it touches and its
+// pattern are recalculated.
+func (h heuristicComponents) update(newState PackedState, tile, from, to int) heuristicComponents {
+	next := h
+	fromRow, fromCol := from/4, from%4
+	toRow, toCol := to/4, to%4
+
+	gr, gc := goalRow(tile), goalCol(tile)
+	next.manhattan += abs(toRow-gr) + abs(toCol-gc) - abs(fromRow-gr) - abs(fromCol-gc)
+
+	if fromRow != toRow {
+		// Vertical slide: the order inside the column is unchanged, only the two rows change.
+		next.rowConflicts[fromRow] = rowConflict(newState, fromRow)
+		next.rowConflicts[toRow] = rowConflict(newState, toRow)
+		next.wdRows += 1<<countShift(wdRowMap[toRow], wdRowMap[gr]) - 1<<countShift(wdRowMap[fromRow], wdRowMap[gr])
+	} else {
+		next.colConflicts[fromCol] = colConflict(newState, fromCol)
+		next.colConflicts[toCol] = colConflict(newState, toCol)
+		next.wdCols += 1<<countShift(wdColMap[toCol], wdColMap[gc]) - 1<<countShift(wdColMap[fromCol], wdColMap[gc])
+	}
+
+	if h.usePDB {
+		p := patternOfTile[tile][0]
+		next.pdb[p] = patternValue(newState, p)
+	}
+	return next
+}
+
+// value combines the components the same way heuristic does for the selected mode.
+func (h heuristicComponents) value(state PackedState) int {
+	if h.usePDB {
+		total := 0
+		for _, v := range h.pdb {
+			total += v
+		}
+		return total
+	}
+
+	linearConflictValue := 0
+	for i := 0; i < 4; i++ {
+		linearConflictValue += h.rowConflicts[i] + h.colConflicts[i]
+	}
+	walkingDistanceValue := 0
+	if walkingRows {
+		if walkingTable != nil {
+			walkingDistanceValue += int(walkingTable[walkingIndex(h.wdRows)])
+		}
+	} else if wdRowTable != nil {
+		walkingDistanceValue += wdRowTable.lookupPacked(h.wdRows)
+	}
+	if walkingCols {
+		if walkingTable != nil {
+			walkingDistanceValue += int(walkingTable[walkingIndex(h.wdCols)])
+		}
+	} else if wdColTable != nil {
+		walkingDistanceValue += wdColTable.lookupPacked(h.wdCols)
+	}
+
+	if optimalMode {
+		heuristicValue := h.manhattan + linearConflictValue
+		if walkingDistanceValue > heuristicValue {
+			heuristicValue = walkingDistanceValue
+		}
+		return heuristicValue
+	}
+
+	heuristicValue := (h.manhattan / 3) + linearConflictValue + walkingDistanceValue
+	if extraHeuristic {
+		heuristicValue += cornerConflict(state.unpack()) / 2
+	}
+	return heuristicValue
+}
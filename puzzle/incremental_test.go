@@ -0,0 +1,26 @@
+package puzzle
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestIncrementalComponents checks that the components updated move by move along a random
+// walk are the same as those computed from scratch for each state.
+func TestIncrementalComponents(t *testing.T) {
+	if err := Configure(Options{}); err != nil {
+		t.Fatal(err)
+	}
+	random := rand.New(rand.NewSource(1))
+	state := packState(StandardGoal(4, 4).State())
+	h := newHeuristicComponents(state)
+	for step := 0; step < 1000; step++ {
+		moves := legalMoves[state.blank]
+		next, tile, from, _ := state.slide(moves[random.Intn(len(moves))])
+		h = h.update(next, tile, from, int(state.blank))
+		state = next
+		if want := newHeuristicComponents(state); h != want {
+			t.Fatalf("step %d, %v: updated components %+v, recomputed %+v", step, state.unpack(), h, want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package puzzle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// nodeCheckInterval is how many nodes a search generates between two checks of its context.
+const nodeCheckInterval = 1024
+
+// searchLimits stops a search when its context is done or when it has generated maxNodes
+// nodes. A single value is shared by all the workers of a parallel search: with a node
+// budget each worker takes its nodes from the shared count one by one, so together they
+// generate exactly maxNodes nodes, and every worker sees the stop flag on its next node.
+type searchLimits struct {
+	ctx      context.Context
+	maxNodes int64
+	// nodes counts the nodes reported by every caller of exceeded and flush.
+	nodes int64
+	// stop is set to 1, atomically, once the search must end.
+	stop   int32
+	once   sync.Once
+	reason string
+}
+
+// newSearchLimits returns the limits of a search run under ctx with the maxNodes budget.
+func newSearchLimits(ctx context.Context) *searchLimits {
+	return &searchLimits{ctx: ctx, maxNodes: int64(maxNodes)}
+}
+
+// exceeded reports whether the search must stop instead of generating one more node; if
+// not, the caller generates it and adds it to generated. generated is the caller's own
+// node counter and reported how much of it was already added to the shared count. The
+// context is only checked every nodeCheckInterval nodes.
+func (l *searchLimits) exceeded(generated int, reported *int) bool {
+	if atomic.LoadInt32(&l.stop) != 0 {
+		return true
+	}
+	if l.maxNodes > 0 {
+		// The next node is counted before it is generated, so no worker goes past the
+		// budget.
+		if atomic.AddInt64(&l.nodes, int64(generated-*reported)+1) > l.maxNodes {
+			l.halt(fmt.Sprintf("se alcanzó el límite de %d nodos generados", l.maxNodes))
+			return true
+		}
+		*reported = generated + 1
+	}
+	if generated%nodeCheckInterval != 0 {
+		return false
+	}
+	if err := l.ctx.Err(); err != nil {
+		l.halt(stopReason(err))
+		return true
+	}
+	return false
+}
+
+// flush adds to the shared count the nodes of a caller that will not call exceeded again,
+// such as a finished task of the parallel search.
+func (l *searchLimits) flush(generated int, reported *int) {
+	atomic.AddInt64(&l.nodes, int64(generated-*reported))
+	*reported = generated
+}
+
+// stopped reports whether the search has already been stopped.
+func (l *searchLimits) stopped() bool {
+	return atomic.LoadInt32(&l.stop) != 0
+}
+
+// halt stops the search; only the first reason is kept.
+func (l *searchLimits) halt(reason string) {
+	l.once.Do(func() {
+		l.reason = reason
+		atomic.StoreInt32(&l.stop, 1)
+	})
+}
+
+// stopReason explains why a context ended.
+func stopReason(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "se agotó el tiempo disponible (-timeout)"
+	}
+	return "búsqueda cancelada"
+}
+
+// partialPath keeps the path to the state with the lowest heuristic seen by a search,
+// reported as the best partial solution when the search stops without reaching the goal.
+// The zero value is empty and any state improves it.
+type partialPath struct {
+	h    int
+	path []PackedState
+}
+
+// offer records path if its last state has a lower heuristic than the best one so far.
+// The path is copied because the searches reuse their path slices.
+func (p *partialPath) offer(h int, path []PackedState) {
+	if p.path == nil || h < p.h {
+		p.h = h
+		p.path = append(p.path[:0], path...)
+	}
+}
+
+// merge keeps the better of p and other.
+func (p *partialPath) merge(other partialPath) {
+	if other.path != nil && (p.path == nil || other.h < p.h) {
+		p.h = other.h
+		p.path = append(p.path[:0], other.path...)
+	}
+}
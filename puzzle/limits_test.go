@@ -0,0 +1,48 @@
+package puzzle
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMaxNodes(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       Options
+		rows, cols int
+		board      string
+	}{
+		{name: "idastar", opts: Options{}},
+		{name: "parallel", opts: Options{Workers: 4}},
+		{name: "parallel with transposition table", opts: Options{Workers: 4, TTMegabytes: 4}},
+		{name: "astar", opts: Options{Algorithm: "astar"}},
+		{name: "rbfs", opts: Options{Algorithm: "rbfs"}},
+		{name: "bfs", opts: Options{Algorithm: "bfs"}},
+		{name: "multi", opts: Options{Metric: MetricMulti}},
+		{name: "3x5", rows: 3, cols: 5, board: "8 6 4 13 3 7 14 12 2 0 9 1 10 5 11"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, cols, board := 4, 4, "1 6 8 2 11 15 7 3 14 13 9 12 5 4 0 10"
+			if test.board != "" {
+				rows, cols, board = test.rows, test.cols, test.board
+			}
+			b, err := ParseBoard(board, rows, cols)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, limit := range []int{1, 7, 5000} {
+				opts := test.opts
+				opts.Heuristic, opts.MaxNodes = HeuristicAdmissible, limit
+				result, err := Solve(context.Background(), b, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.Solved || result.Generated != limit || result.Quality != "" || result.Stopped == "" {
+					t.Errorf("MaxNodes %d: solved %v, generated %d, quality %q, stopped %q",
+						limit, result.Solved, result.Generated, result.Quality, result.Stopped)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package puzzle
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Names of the walking distance table in Tables: the binary table used by the solver and
+// the JSON table it was originally distributed as.
+const (
+	walkingDistanceFile = "walking_distance.bin"
+	walkingDistanceJSON = "matrix_states.json"
+)
+
+// saveResults saves the state distances to a JSON file
+func saveResults(distances map[string]int, filename string) error {
+	// Convert to map with string keys
+	hashMap := make(map[string]int)
+	for key, dist := range distances {
+		hashMap[key] = dist
+	}
+
+	data, err := json.MarshalIndent(hashMap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("JSON marshaling failed: %w", err)
+	}
+
+	return os.WriteFile(filename, data, 0644)
+}
+
+// GenerateMovingDistances genera la tabla binaria de walking distance en las tablas de la
+// configuración (ver Configure) si no existe. Si solo está la tabla JSON, la convierte. Si
+// falta y está en Tables.Fallback (la copia embebida en el binario) se usa esa copia; si
+// falta y la generación está desactivada (Tables.NoGenerate) devuelve ErrMissingTable.
+func GenerateMovingDistances() error {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return generateMovingDistances()
+}
+
+// generateMovingDistances is GenerateMovingDistances without the lock.
+func generateMovingDistances() error {
+	// Verificar si el archivo ya existe
+	if tables.has(walkingDistanceFile) {
+		logf("El archivo ya ha sido generado, no se generará nuevamente.\n")
+		return nil
+	}
+	if tables.has(walkingDistanceJSON) {
+		if tables.NoGenerate {
+			// loadWalkingTable convierte la tabla JSON en memoria.
+			return nil
+		}
+		logf("Convirtiendo %s a %s...\n", tables.path(walkingDistanceJSON), tables.path(walkingDistanceFile))
+		data, err := tables.read(walkingDistanceJSON)
+		if err != nil {
+			return fmt.Errorf("error reading file: %v", err)
+		}
+		entries, err := decodeWalkingJSON(data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", tables.path(walkingDistanceJSON), err)
+		}
+		return tables.store(walkingDistanceFile, encodeTable(tableWalkingDistance, 4, 4, entries))
+	}
+	if tables.hasFallback(walkingDistanceFile) {
+		logf("Usando la tabla de walking distance embebida en el binario.\n")
+		return nil
+	}
+	if tables.NoGenerate {
+		return tables.missing("la tabla de walking distance", walkingDistanceFile)
+	}
+	logf("El archivo no existe, generándolo ahora...\n")
+
+	// BFS over the walking distance states of a 4x4 board whose blank ends in the last row,
+	// stored with the dense index of walkingIndex.
+	table, err := buildWalkingTable(walkingSpec{lines: 4, width: 4, blank: 3})
+	if err != nil {
+		return err
+	}
+	entries, states, maxDistance := legacyWalkingEntries(table)
+	if err := tables.store(walkingDistanceFile, encodeTable(tableWalkingDistance, 4, 4, entries)); err != nil {
+		return fmt.Errorf("error saving results: %w", err)
+	}
+
+	// Display statistics
+	logf("Total generated states: %d\n", states)
+	logf("Maximum walking distance: %d\n", maxDistance)
+	return nil
+}
+
+// legacyWalkingEntries converts a 4x4 table of buildWalkingTable into the dense entries of
+// walking_distance.bin and returns them with the number of states and the largest distance.
+func legacyWalkingEntries(table *wdTable) (entries []byte, states, maxDistance int) {
+	entries = bytes.Repeat([]byte{tableUnreachable}, walkingEntries)
+	counts := make([]int, 16)
+	for index, distance := range table.entries {
+		if distance == tableUnreachable {
+			continue
+		}
+		table.unrank(index, counts)
+		var packed uint64
+		for cell, count := range counts {
+			packed |= uint64(count) << countShift(cell/4, cell%4)
+		}
+		entries[walkingIndex(packed)] = distance
+		states++
+		if int(distance) > maxDistance {
+			maxDistance = int(distance)
+		}
+	}
+	return entries, states, maxDistance
+}
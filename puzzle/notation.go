@@ -0,0 +1,86 @@
+package puzzle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Conventions accepted by FormatMoves and ParseMoves to write a move sequence.
+const (
+	// NotationBlank writes the direction in which the blank moves.
+	NotationBlank = "blank"
+	// NotationTile writes the direction in which the tile slides, the opposite of the blank.
+	NotationTile = "tile"
+)
+
+// movesFromPath recovers the moves of the blank between consecutive states of a path.
+func movesFromPath(path []State) ([]Move, error) {
+	moves := make([]Move, 0, len(path))
+	for k := 1; k < len(path); k++ {
+		i, j := findBlank(path[k-1])
+		ni, nj := findBlank(path[k])
+		found := false
+		for m := Up; m <= Right; m++ {
+			if i+moveOffsets[m][0] == ni && j+moveOffsets[m][1] == nj {
+				moves = append(moves, m)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("states %d and %d are not one move apart", k-1, k)
+		}
+	}
+	return moves, nil
+}
+
+// MoveCount returns the number of moves of a sequence of single-tile moves in a metric:
+// len(moves) in MetricSingle, and in MetricMulti the number of runs of equal moves, since
+// sliding several tiles of a row or column at once counts as one move.
+func MoveCount(moves []Move, metric string) int {
+	if metric != MetricMulti {
+		return len(moves)
+	}
+	count := 0
+	for i, m := range moves {
+		if i == 0 || m != moves[i-1] {
+			count++
+		}
+	}
+	return count
+}
+
+// FormatMetricMoves writes a move sequence like FormatMoves; in MetricMulti each run of
+// equal moves is written once followed by its length when it is longer than one, such as
+// "RD2LUR". ParseMoves reads both forms.
+func FormatMetricMoves(moves []Move, notation, metric string) string {
+	if metric != MetricMulti {
+		return FormatMoves(moves, notation)
+	}
+	var b strings.Builder
+	for i := 0; i < len(moves); {
+		j := i
+		for j < len(moves) && moves[j] == moves[i] {
+			j++
+		}
+		b.WriteString(FormatMoves(moves[i:i+1], notation))
+		if j-i > 1 {
+			fmt.Fprintf(&b, "%d", j-i)
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// FormatMoves writes a move sequence in compact notation, such as "RDDLUR", using the
+// blank-direction or tile-direction convention.
+func FormatMoves(moves []Move, notation string) string {
+	var b strings.Builder
+	for _, m := range moves {
+		if notation == NotationTile {
+			m = opposite(m)
+		}
+		b.WriteByte(moveLetters[m])
+	}
+	return b.String()
+}
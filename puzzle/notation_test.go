@@ -0,0 +1,68 @@
+package puzzle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormatMoves(t *testing.T) {
+	tests := []struct {
+		moves    []Move
+		notation string
+		metric   string
+		want     string
+	}{
+		{nil, NotationBlank, MetricSingle, ""},
+		{[]Move{Right, Down, Down, Left, Up, Right}, NotationBlank, MetricSingle, "RDDLUR"},
+		{[]Move{Right, Down, Down, Left, Up, Right}, NotationTile, MetricSingle, "LUURDL"},
+		{[]Move{Right, Down, Down, Left, Up, Right}, NotationBlank, MetricMulti, "RD2LUR"},
+		{[]Move{Up, Up, Up, Left, Left}, NotationTile, MetricMulti, "D3R2"},
+	}
+	for _, test := range tests {
+		got := FormatMetricMoves(test.moves, test.notation, test.metric)
+		if got != test.want {
+			t.Errorf("FormatMetricMoves(%v, %s, %s) = %q, want %q", test.moves, test.notation, test.metric, got, test.want)
+		}
+		parsed, err := ParseMoves(got, test.notation)
+		if err != nil {
+			t.Errorf("ParseMoves(%q, %s): %v", got, test.notation, err)
+		} else if !reflect.DeepEqual(parsed, test.moves) {
+			t.Errorf("ParseMoves(%q, %s) = %v, want %v", got, test.notation, parsed, test.moves)
+		}
+	}
+}
+
+func TestMoveCount(t *testing.T) {
+	moves := []Move{Right, Down, Down, Left, Up, Right}
+	if got := MoveCount(moves, MetricSingle); got != 6 {
+		t.Errorf("MoveCount single = %d, want 6", got)
+	}
+	if got := MoveCount(moves, MetricMulti); got != 5 {
+		t.Errorf("MoveCount multi = %d, want 5", got)
+	}
+}
+
+func TestMovesFromPath(t *testing.T) {
+	if err := Configure(Options{}); err != nil {
+		t.Fatal(err)
+	}
+	start := mustBoard(t, "5 1 2 4 9 6 3 8 13 10 7 11 14 0 15 12", 4, 4)
+	moves, err := ParseMoves("UUURDDRD", NotationBlank)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var path []State
+	for _, b := range replayMoves(start, moves) {
+		path = append(path, b.State())
+	}
+	got, err := movesFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, moves) {
+		t.Errorf("movesFromPath = %v, want %v", got, moves)
+	}
+	if _, err := movesFromPath([]State{path[0], path[2]}); err == nil {
+		t.Error("movesFromPath accepted states two moves apart")
+	}
+}
@@ -0,0 +1,307 @@
+package puzzle
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// noPath is the distance between two cells that no tile can join.
+const noPath = 1 << 20
+
+// Obstacles are the fixed parts of a physical board: blocked cells, which always hold the
+// tile of the goal and never move, and walls between two neighboring cells, which neither
+// the blank nor a tile can cross. Cells are numbered from 0, row by row.
+type Obstacles struct {
+	Rows, Cols int
+	Blocked    []int
+	Walls      [][2]int
+
+	// blocked[cell] reports whether cell is blocked; closed[cell][m] whether the blank
+	// cannot leave cell in direction m (the edge of the board, a blocked cell or a wall).
+	blocked []bool
+	closed  [][4]bool
+	// distances[from*cells+to] is the true distance of a tile between two cells (noPath if
+	// it cannot get there), the heuristic used instead of the Manhattan Distance.
+	distances []int
+	// parity is whether the parity rule still decides which boards are solvable (see
+	// parityApplies); otherwise reachable searches.
+	parity bool
+}
+
+// currentObstacles are the obstacles of the configuration (Options.Obstacles, nil without
+// obstacles).
+var currentObstacles *Obstacles
+
+// NewObstacles checks the blocked cells and the walls of a rows x cols board and
+// precomputes the true distances between its cells.
+func NewObstacles(rows, cols int, blocked []int, walls [][2]int) (*Obstacles, error) {
+	n := rows * cols
+	o := &Obstacles{Rows: rows, Cols: cols, Blocked: blocked, Walls: walls,
+		blocked: make([]bool, n), closed: make([][4]bool, n)}
+	for _, cell := range blocked {
+		if cell < 0 || cell >= n {
+			return nil, fmt.Errorf("casilla bloqueada fuera del tablero %dx%d: %d", rows, cols, cell)
+		}
+		o.blocked[cell] = true
+	}
+	for cell := 0; cell < n; cell++ {
+		for m := Up; m <= Right; m++ {
+			i, j := cell/cols+moveOffsets[m][0], cell%cols+moveOffsets[m][1]
+			o.closed[cell][m] = i < 0 || i >= rows || j < 0 || j >= cols || o.blocked[cell] || o.blocked[i*cols+j]
+		}
+	}
+	for _, wall := range walls {
+		m, ok := o.direction(wall[0], wall[1])
+		if !ok {
+			return nil, fmt.Errorf("pared inválida %d-%d: las casillas deben ser vecinas", wall[0], wall[1])
+		}
+		o.closed[wall[0]][m] = true
+		o.closed[wall[1]][opposite(m)] = true
+	}
+
+	o.distances = make([]int, n*n)
+	for from := 0; from < n; from++ {
+		o.bfs(from, o.distances[from*n:(from+1)*n])
+	}
+	o.parity = o.parityApplies()
+	return o, nil
+}
+
+// ParseObstacles reads the blocked cells ("5 10" or "5,10") and the walls ("1-2 5-9",
+// pairs of neighboring cells) of a rows x cols board. Both empty means no obstacles (nil).
+func ParseObstacles(blocked, walls string, rows, cols int) (*Obstacles, error) {
+	if strings.TrimSpace(blocked) == "" && strings.TrimSpace(walls) == "" {
+		return nil, nil
+	}
+	fields := func(text string) []string {
+		return strings.Fields(strings.ReplaceAll(text, ",", " "))
+	}
+	var cells []int
+	for _, field := range fields(blocked) {
+		cell, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("casilla bloqueada inválida: %s", field)
+		}
+		cells = append(cells, cell)
+	}
+	var pairs [][2]int
+	for _, field := range fields(walls) {
+		parts := strings.Split(field, "-")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("pared inválida: %s (se esperaba CASILLA-CASILLA)", field)
+		}
+		a, err1 := strconv.Atoi(parts[0])
+		b, err2 := strconv.Atoi(parts[1])
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("pared inválida: %s (se esperaba CASILLA-CASILLA)", field)
+		}
+		pairs = append(pairs, [2]int{a, b})
+	}
+	return NewObstacles(rows, cols, cells, pairs)
+}
+
+// obstaclesFor returns currentObstacles if they have the given size and nil otherwise.
+func obstaclesFor(rows, cols int) *Obstacles {
+	if currentObstacles != nil && currentObstacles.Rows == rows && currentObstacles.Cols == cols {
+		return currentObstacles
+	}
+	return nil
+}
+
+// direction returns the move that goes from cell a to its neighbor b.
+func (o *Obstacles) direction(a, b int) (Move, bool) {
+	n := o.Rows * o.Cols
+	if a < 0 || a >= n || b < 0 || b >= n {
+		return 0, false
+	}
+	for m := Up; m <= Right; m++ {
+		i, j := a/o.Cols+moveOffsets[m][0], a%o.Cols+moveOffsets[m][1]
+		if i >= 0 && i < o.Rows && j >= 0 && j < o.Cols && i*o.Cols+j == b {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+// neighbor returns the cell the blank reaches from cell with m, or -1 if it cannot move.
+func (o *Obstacles) neighbor(cell int, m Move) int {
+	if o.closed[cell][m] {
+		return -1
+	}
+	return cell + moveOffsets[m][0]*o.Cols + moveOffsets[m][1]
+}
+
+// bfs fills distances with the number of moves a tile needs to go from cell to every
+// other cell when nothing else is in its way.
+func (o *Obstacles) bfs(cell int, distances []int) {
+	for i := range distances {
+		distances[i] = noPath
+	}
+	distances[cell] = 0
+	queue := []int{cell}
+	for len(queue) > 0 {
+		from := queue[0]
+		queue = queue[1:]
+		for m := Up; m <= Right; m++ {
+			if to := o.neighbor(from, m); to != -1 && distances[to] == noPath {
+				distances[to] = distances[from] + 1
+				queue = append(queue, to)
+			}
+		}
+	}
+}
+
+// distance is the true distance of a tile between two cells.
+func (o *Obstacles) distance(from, to int) int {
+	return o.distances[from*o.Rows*o.Cols+to]
+}
+
+// parityApplies reports whether the free cells form a graph where the parity rule holds:
+// by Wilson's theorem, in a 2-connected graph that is not a cycle, as a grid without
+// obstacles, the blank reaches every board of the parity class of its position. Grids
+// are bipartite, so the exceptional graph of the theorem never appears.
+func (o *Obstacles) parityApplies() bool {
+	free, edges := 0, 0
+	for cell, blocked := range o.blocked {
+		if blocked {
+			continue
+		}
+		free++
+		for m := Up; m <= Right; m++ {
+			if o.neighbor(cell, m) != -1 {
+				edges++
+			}
+		}
+	}
+	edges /= 2
+	if free < 3 || edges == free || !o.connected(-1) {
+		// Too small, a single cycle (connected with as many edges as cells) or split.
+		return false
+	}
+	for cell, blocked := range o.blocked {
+		if !blocked && !o.connected(cell) {
+			return false
+		}
+	}
+	return true
+}
+
+// connected reports whether the free cells other than skip (-1 for none) are connected.
+func (o *Obstacles) connected(skip int) bool {
+	start := -1
+	for cell, blocked := range o.blocked {
+		if !blocked && cell != skip {
+			start = cell
+			break
+		}
+	}
+	seen := make([]bool, len(o.blocked))
+	seen[start] = true
+	queue, count := []int{start}, 1
+	for len(queue) > 0 {
+		from := queue[0]
+		queue = queue[1:]
+		for m := Up; m <= Right; m++ {
+			if to := o.neighbor(from, m); to != -1 && to != skip && !seen[to] {
+				seen[to] = true
+				queue = append(queue, to)
+				count++
+			}
+		}
+	}
+	free := 0
+	for cell, blocked := range o.blocked {
+		if !blocked && cell != skip {
+			free++
+		}
+	}
+	return count == free
+}
+
+// check verifies that every blocked cell of b holds the tile of the goal g.
+func (o *Obstacles) check(b Board, g *Goal) error {
+	for _, cell := range o.Blocked {
+		if g.Tiles[cell] == 0 {
+			return fmt.Errorf("el objetivo tiene el espacio vacío en la casilla bloqueada %d", cell)
+		}
+		if b.Tiles[cell] != g.Tiles[cell] {
+			return fmt.Errorf("la casilla bloqueada %d debe tener la ficha %d del objetivo", cell, g.Tiles[cell])
+		}
+	}
+	return nil
+}
+
+// reachable reports whether the goal g can be reached from b. When the parity rule
+// applies, b must be in the parity class of g: the parity of the permutation between
+// them must match the parity of the distance between their blanks. Otherwise it searches
+// (see search), and the error tells when the search could not decide.
+func (o *Obstacles) reachable(ctx context.Context, b Board, g *Goal) (bool, error) {
+	if o.check(b, g) != nil {
+		return false, nil
+	}
+	if o.parity {
+		return permutationParity(b.Tiles, g) == abs(b.blank()/b.Cols-g.row(0)+b.blank()%b.Cols-g.col(0))%2, nil
+	}
+	return o.search(ctx, b, tilesKey(g.Tiles))
+}
+
+// search explores the boards reachable from b, breadth first, until it finds the goal (the
+// key of its tiles) or runs out of boards. Past maxStates boards it gives up with
+// ErrUndecided, and it returns ctx.Err() if ctx ends first.
+func (o *Obstacles) search(ctx context.Context, b Board, goal string) (bool, error) {
+	seen := map[string]bool{tilesKey(b.Tiles): true}
+	queue := []Board{b}
+	for expanded := 0; len(queue) > 0; expanded++ {
+		if expanded%nodeCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+		}
+		current := queue[0]
+		queue = queue[1:]
+		if tilesKey(current.Tiles) == goal {
+			return true, nil
+		}
+		for m := Up; m <= Right; m++ {
+			next, ok := current.move(m)
+			if !ok || seen[tilesKey(next.Tiles)] {
+				continue
+			}
+			if len(seen) >= maxStates {
+				return false, fmt.Errorf("%w con %d estados", ErrUndecided, maxStates)
+			}
+			seen[tilesKey(next.Tiles)] = true
+			queue = append(queue, next)
+		}
+	}
+	return false, nil
+}
+
+// permutationParity is the parity of the permutation that takes every tile of tiles to
+// its cell in g.
+func permutationParity(tiles []int, g *Goal) int {
+	visited := make([]bool, len(tiles))
+	parity := 0
+	for start := range tiles {
+		length := 0
+		for cell := start; !visited[cell]; cell = g.cell(tiles[cell]) {
+			visited[cell] = true
+			length++
+		}
+		if length > 0 {
+			parity += length - 1
+		}
+	}
+	return parity % 2
+}
+
+// tilesKey packs the tiles of a board (at most 64 cells) into a string map key.
+func tilesKey(tiles []int) string {
+	key := make([]byte, len(tiles))
+	for i, v := range tiles {
+		key[i] = byte(v)
+	}
+	return string(key)
+}
@@ -0,0 +1,75 @@
+package puzzle
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSolvabilitySearch(t *testing.T) {
+	walls, err := ParseObstacles("", "0-1", 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if walls.parity {
+		t.Fatal("the wall 0-1 should break the parity rule of 3x3")
+	}
+	near, err := ParseBoard("1 2 3 4 5 6 7 0 8", 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	far, err := ParseBoard("8 6 7 2 5 4 3 0 1", 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	swapped, err := ParseBoard("2 1 3 4 5 6 7 8 0", 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		maxStates int
+		board     Board
+		solvable  bool
+		err       error
+	}{
+		{"near", ctx, 0, near, true, nil},
+		{"unsolvable", ctx, 0, swapped, false, nil},
+		{"undecided", ctx, 10, far, false, ErrUndecided},
+		{"cancelled", cancelled, 0, far, false, context.Canceled},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := Configure(Options{Obstacles: walls, MaxStates: test.maxStates}); err != nil {
+				t.Fatal(err)
+			}
+			solvable, err := test.board.IsSolvable(test.ctx)
+			if solvable != test.solvable || !errors.Is(err, test.err) {
+				t.Errorf("IsSolvable = %v, %v; want %v, %v", solvable, err, test.solvable, test.err)
+			}
+		})
+	}
+
+	t.Run("solve undecided", func(t *testing.T) {
+		result, err := Solve(ctx, far, Options{Obstacles: walls, MaxStates: 10, MaxNodes: 100})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Solved || result.Generated == 0 {
+			t.Errorf("solved %v, generated %d: the search should run anyway", result.Solved, result.Generated)
+		}
+	})
+	t.Run("solve cancelled", func(t *testing.T) {
+		result, err := Solve(cancelled, far, Options{Obstacles: walls})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Solved || result.Stopped != "búsqueda cancelada" {
+			t.Errorf("solved %v, stopped %q", result.Solved, result.Stopped)
+		}
+	})
+}
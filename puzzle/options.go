@@ -0,0 +1,378 @@
+// Package puzzle solves sliding puzzles: the 15-puzzle with IDA*, A*, weighted A*, BFS,
+// RBFS or anytime weighted A* and the walking distance or pattern database heuristics, and
+// boards of other sizes (2x2 to 8x8) with IDA*.
+//
+// A board is read with ParseBoard and solved with Solve:
+//
+//	b, err := puzzle.ParseBoard("2 15 4 12 9 5 10 14 1 6 0 7 8 3 13 11", 4, 4)
+//	result, err := puzzle.Solve(ctx, b, puzzle.Options{
+//		Heuristic: puzzle.HeuristicAdmissible,
+//		Tables:    puzzle.DirTables("tables"),
+//	})
+//
+// The heuristic tables are read from Options.Tables and generated there when missing.
+// The solver keeps its configuration and its tables in package variables, so Solve calls
+// run one at a time, and so do the other exported functions that read the configuration
+// (the Board heuristics, IsSolvable, GenerateRandom, the verifiers...).
+package puzzle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Heuristics accepted by Options.Heuristic.
+const (
+	// HeuristicDefault is (Manhattan / 3) + Linear Conflict + Walking Distance: fast but
+	// not admissible.
+	HeuristicDefault = "default"
+	// HeuristicExtra adds Corner Conflict / 2 to HeuristicDefault.
+	HeuristicExtra = "extra"
+	// HeuristicAdmissible is max(Walking Distance, Manhattan + Linear Conflict), so the
+	// solutions of IDA* and A* are optimal.
+	HeuristicAdmissible = "admissible"
+	// HeuristicPDB adds up the additive pattern databases of Options.Partition; it is
+	// admissible too.
+	HeuristicPDB = "pdb"
+)
+
+// Move metrics accepted by Options.Metric.
+const (
+	// MetricSingle counts every tile that slides as one move.
+	MetricSingle = "single"
+	// MetricMulti counts sliding a segment of a row or column toward the blank as one
+	// move, however many tiles it holds.
+	MetricMulti = "multi"
+)
+
+// ErrUnsolvable is returned by Solve when the goal cannot be reached from the board.
+var ErrUnsolvable = errors.New("el puzzle no tiene solución")
+
+// ErrUndecided is returned by Board.IsSolvable when the search of a board with walls gives
+// up past Options.MaxStates boards without deciding whether the goal can be reached.
+var ErrUndecided = errors.New("no se pudo decidir si el puzzle tiene solución")
+
+// Options configures Solve. The zero value solves with sequential IDA* and the default
+// heuristic, towards the standard goal, keeping the tables it generates in memory.
+type Options struct {
+	// Algorithm is idastar (default), astar, wastar, bfs, rbfs or anytime. Boards other
+	// than 4x4 only support idastar.
+	Algorithm string
+	// Heuristic is one of the Heuristic* constants (HeuristicDefault if empty). Boards
+	// other than 4x4 always use max(Walking Distance, Manhattan + Linear Conflict).
+	Heuristic string
+	// Partition is the pattern database partition of HeuristicPDB (DefaultPartition if
+	// empty, see Partitions).
+	Partition string
+	// Goal is the goal of the boards of its size (the standard goal if nil).
+	Goal *Goal
+	// Metric is the move metric, MetricSingle (default) or MetricMulti. With MetricMulti
+	// every size is solved with IDA* and a heuristic admissible in that metric, and
+	// Options.Heuristic is ignored.
+	Metric string
+	// Obstacles are the blocked cells and walls of the boards of their size (nil without
+	// obstacles). Those boards are solved with IDA* in the single-tile metric and a
+	// heuristic based on the true distances of the tiles around the obstacles.
+	Obstacles *Obstacles
+
+	// Weight is the weight of the heuristic in wastar and anytime (2 if 0, at least 1).
+	Weight float64
+	// MaxStates is the largest number of states astar, wastar, bfs and anytime keep in
+	// memory, and of boards the solvability search with walls explores (DefaultMaxStates
+	// if 0).
+	MaxStates int
+	// TimeLimit is the time given to anytime (0 without limit).
+	TimeLimit time.Duration
+	// Timeout stops any search after this time (0 without limit).
+	Timeout time.Duration
+	// MaxNodes stops any search after generating this many states (0 without limit).
+	MaxNodes int
+
+	// Workers runs IDA* in parallel with this many goroutines (0 or 1 is sequential).
+	Workers int
+	// TTMegabytes gives IDA* a transposition table of this many MB (0 disables it).
+	TTMegabytes int
+	// TTPolicy is the replacement policy of the transposition table, "shallow" (default)
+	// or "always".
+	TTPolicy string
+
+	// Tables is where the heuristic tables are read and generated (in memory if nil).
+	Tables *Tables
+	// Progress receives one event per IDA* iteration (nil discards them).
+	Progress ProgressFunc
+	// Improved receives each shorter solution found by anytime (nil discards them).
+	Improved ImprovementFunc
+	// Log receives the messages about the tables and the solutions found by anytime (nil
+	// discards them).
+	Log io.Writer
+}
+
+// Admissible reports whether the heuristic of the options never overestimates, so IDA*
+// and A* return optimal solutions.
+func (o Options) Admissible() bool {
+	return o.Heuristic == HeuristicAdmissible || o.Heuristic == HeuristicPDB || o.Metric == MetricMulti ||
+		o.Obstacles != nil
+}
+
+// Supports checks that the options can solve rows x cols boards: the pattern databases,
+// the algorithms other than IDA*, parallel IDA* and the transposition table are 4x4 only
+// and need the single-tile metric and a board without obstacles.
+func (o Options) Supports(rows, cols int) error {
+	obstacles := o.Obstacles != nil && o.Obstacles.Rows == rows && o.Obstacles.Cols == cols
+	if obstacles && o.Metric == MetricMulti {
+		return fmt.Errorf("las casillas bloqueadas y las paredes no están disponibles en la métrica multi")
+	}
+	if rows == 4 && cols == 4 && o.Metric != MetricMulti && !obstacles {
+		return nil
+	}
+	if o.Heuristic == HeuristicPDB {
+		switch {
+		case o.Metric == MetricMulti:
+			return fmt.Errorf("las bases de patrones no están disponibles en la métrica multi")
+		case obstacles:
+			return fmt.Errorf("las bases de patrones no están disponibles con casillas bloqueadas o paredes")
+		}
+		return fmt.Errorf("las bases de patrones solo están disponibles para tableros 4x4")
+	}
+	if (o.Algorithm != "" && o.Algorithm != "idastar") || o.Workers > 1 || o.TTMegabytes > 0 {
+		switch {
+		case o.Metric == MetricMulti:
+			return fmt.Errorf("la métrica multi solo se resuelve con IDA* secuencial, sin tabla de transposición")
+		case obstacles:
+			return fmt.Errorf("los tableros con casillas bloqueadas o paredes solo se resuelven con IDA* secuencial, sin tabla de transposición")
+		}
+		return fmt.Errorf("los tableros %dx%d solo se resuelven con IDA* secuencial, sin tabla de transposición", rows, cols)
+	}
+	return nil
+}
+
+var (
+	// solveMu serializes every exported function that reads or writes the package state:
+	// Configure, PrepareTables, Solve and the heuristics, generators and checks that depend
+	// on the configuration.
+	solveMu sync.Mutex
+	// defaultTables is the in-memory Tables of the options without Tables.
+	defaultTables = &Tables{}
+	// configured identifies the tables, goal, partition, metric and obstacles the loaded
+	// tables belong to.
+	configured tablesKey
+	// preparedRows and preparedCols are the size whose tables prepareTables loaded last.
+	preparedRows, preparedCols int
+)
+
+// tablesKey is what the loaded heuristic tables depend on.
+type tablesKey struct {
+	tables    *Tables
+	goal      string
+	partition string
+	metric    string
+	obstacles *Obstacles
+}
+
+// Configure validates opts and makes them the configuration of the package functions that
+// do not take Options, such as HeuristicCalculus, Board.HeuristicReport or GenerateRandom.
+// Solve configures the package itself.
+func Configure(opts Options) error {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return configure(opts)
+}
+
+// configure is Configure without the lock.
+func configure(opts Options) error {
+	if opts.Algorithm == "" {
+		opts.Algorithm = "idastar"
+	}
+	if opts.Heuristic == "" {
+		opts.Heuristic = HeuristicDefault
+	}
+	if opts.Weight == 0 {
+		opts.Weight = 2
+	}
+	if opts.MaxStates == 0 {
+		opts.MaxStates = DefaultMaxStates
+	}
+	if opts.TTPolicy == "" {
+		opts.TTPolicy = replaceShallow
+	}
+	if opts.Metric == "" {
+		opts.Metric = MetricSingle
+	}
+	if opts.Heuristic == HeuristicPDB && opts.Partition == "" {
+		opts.Partition = DefaultPartition
+	}
+
+	partition := ""
+	switch opts.Heuristic {
+	case HeuristicDefault, HeuristicExtra, HeuristicAdmissible:
+	case HeuristicPDB:
+		if _, ok := patternPartitions[opts.Partition]; !ok {
+			return fmt.Errorf("partición desconocida: %s (opciones: 6-6-3, 5-5-5)", opts.Partition)
+		}
+		partition = opts.Partition
+	default:
+		return fmt.Errorf("heurística desconocida: %s (opciones: default, extra, admissible, pdb)", opts.Heuristic)
+	}
+	if opts.Metric != MetricSingle && opts.Metric != MetricMulti {
+		return fmt.Errorf("métrica desconocida: %s (opciones: single, multi)", opts.Metric)
+	}
+	goal := opts.Goal
+	if goal == nil {
+		goal = StandardGoal(4, 4)
+	}
+	if o := opts.Obstacles; o != nil {
+		g := goal
+		if g.Rows != o.Rows || g.Cols != o.Cols {
+			g = StandardGoal(o.Rows, o.Cols)
+		}
+		if err := o.check(g.Board, g); err != nil {
+			return err
+		}
+	}
+	if _, ok := solverAlgorithms[opts.Algorithm]; !ok {
+		return fmt.Errorf("algoritmo desconocido: %s (opciones: idastar, astar, wastar, bfs, rbfs, anytime)", opts.Algorithm)
+	}
+	if opts.Weight < 1 {
+		return fmt.Errorf("peso inválido (debe ser >= 1): %v", opts.Weight)
+	}
+	if opts.MaxStates < 1 {
+		return fmt.Errorf("límite de estados inválido: %d", opts.MaxStates)
+	}
+	if opts.TimeLimit < 0 {
+		return fmt.Errorf("tiempo límite inválido: %v", opts.TimeLimit)
+	}
+	if opts.Timeout < 0 {
+		return fmt.Errorf("timeout inválido: %v", opts.Timeout)
+	}
+	if opts.MaxNodes < 0 {
+		return fmt.Errorf("límite de nodos inválido: %d", opts.MaxNodes)
+	}
+	if opts.Workers < 0 {
+		return fmt.Errorf("número de workers inválido: %d", opts.Workers)
+	}
+	if opts.TTMegabytes < 0 {
+		return fmt.Errorf("tamaño de tabla de transposición inválido: %d", opts.TTMegabytes)
+	}
+	if opts.TTPolicy != replaceAlways && opts.TTPolicy != replaceShallow {
+		return fmt.Errorf("política de reemplazo desconocida: %s (opciones: always, shallow)", opts.TTPolicy)
+	}
+
+	algorithm, astarWeight, maxStates = opts.Algorithm, opts.Weight, opts.MaxStates
+	timeLimit, searchTimeout, maxNodes = opts.TimeLimit, opts.Timeout, opts.MaxNodes
+	workers, ttMegabytes, ttPolicy = opts.Workers, opts.TTMegabytes, opts.TTPolicy
+	extraHeuristic = opts.Heuristic == HeuristicExtra
+	optimalMode = opts.Heuristic == HeuristicAdmissible
+	pdbPartition = partition
+	moveMetric = opts.Metric
+	progressFunc, improvementFunc, logOutput = opts.Progress, opts.Improved, opts.Log
+
+	setGoal(goal)
+	currentObstacles = opts.Obstacles
+	tables = opts.Tables
+	if tables == nil {
+		tables = defaultTables
+	}
+	key := tablesKey{tables, fmt.Sprintf("%dx%d %v", goal.Rows, goal.Cols, goal.Tiles), partition, opts.Metric, opts.Obstacles}
+	if key != configured {
+		configured = key
+		resetTables()
+	}
+	return nil
+}
+
+// resetTables forgets the loaded tables whose contents depend on the Tables, the goal or
+// the partition, so the next search loads them again. The walking distance tables of
+// wdTables only depend on their walkingSpec and are kept.
+func resetTables() {
+	walkingTable, loadStatesErr, loadStatesOnce = nil, nil, sync.Once{}
+	patternCache, loadPatternsErr, loadPatternsOnce = nil, nil, sync.Once{}
+	wdRowTable, wdColTable = nil, nil
+	preparedRows, preparedCols = 0, 0
+}
+
+// PrepareTables generates the tables that the configuration (see Configure) needs for
+// rows x cols boards when they are missing and loads them, so a missing or corrupt table
+// is reported before a search starts instead of silently becoming a heuristic of 0.
+func PrepareTables(rows, cols int) error {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return prepareTables(rows, cols)
+}
+
+// prepareTables is PrepareTables without the lock. It does nothing if the tables of the
+// size are already loaded.
+func prepareTables(rows, cols int) error {
+	if rows == preparedRows && cols == preparedCols {
+		return nil
+	}
+	if rows != 4 || cols != 4 || moveMetric == MetricMulti || obstaclesFor(4, 4) != nil {
+		// Only the walking distance tables of the size, goal and metric, if they can be
+		// generated.
+		if err := prepareWalkingTables(goalFor(rows, cols)); err != nil {
+			return err
+		}
+		preparedRows, preparedCols = rows, cols
+		return nil
+	}
+	if err := generateMovingDistances(); err != nil {
+		return err
+	}
+	if err := loadWalkingTable(); err != nil {
+		return fmt.Errorf("error loading walking distance table: %w", err)
+	}
+	if err := prepareWalkingTables(goalFor(4, 4)); err != nil {
+		return err
+	}
+	if pdbPartition != "" {
+		if err := generatePatternDatabases(pdbPartition); err != nil {
+			return fmt.Errorf("error generating pattern databases: %w", err)
+		}
+		if err := loadPatternTables(); err != nil {
+			return fmt.Errorf("error loading pattern databases: %w", err)
+		}
+	}
+	preparedRows, preparedCols = rows, cols
+	return nil
+}
+
+// Solve solves b with opts: it validates the board and the options, prepares the tables
+// and runs the search. It returns ErrUnsolvable if the goal cannot be reached from b; a
+// board whose solvability cannot be decided (see ErrUndecided) is searched anyway. A
+// search stopped by ctx, Options.Timeout or Options.MaxNodes is not an error: the Result
+// is not Solved and holds the reason in Stopped and the best partial path found.
+func Solve(ctx context.Context, b Board, opts Options) (Result, error) {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	if err := b.Validate(); err != nil {
+		return Result{}, err
+	}
+	if err := opts.Supports(b.Rows, b.Cols); err != nil {
+		return Result{}, err
+	}
+	if err := configure(opts); err != nil {
+		return Result{}, err
+	}
+	if err := prepareTables(b.Rows, b.Cols); err != nil {
+		return Result{}, err
+	}
+	if err := b.checkObstacles(); err != nil {
+		return Result{}, err
+	}
+	// The timeout also covers the solvability check, which can search on boards with walls.
+	ctx, cancel := withSearchTimeout(ctx)
+	defer cancel()
+	solvable, err := b.isSolvable(ctx)
+	switch {
+	case errors.Is(err, ErrUndecided):
+		logf("%v; se intenta resolverlo.\n", err)
+	case err != nil:
+		return Result{Algorithm: algorithm, Stopped: stopReason(err)}, nil
+	case !solvable:
+		return Result{}, ErrUnsolvable
+	}
+	return solveBoard(ctx, b, algorithm), nil
+}
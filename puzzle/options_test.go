@@ -0,0 +1,61 @@
+package puzzle
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentEntryPoints runs Solve with different configurations next to the other
+// functions that read the configuration; run it with -race.
+func TestConcurrentEntryPoints(t *testing.T) {
+	b, err := ParseBoard("1 6 8 2 11 15 7 3 14 13 9 12 5 4 0 10", 4, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for _, opts := range []Options{
+		{Heuristic: HeuristicAdmissible, MaxNodes: 2000},
+		{Metric: MetricMulti, MaxNodes: 2000},
+		{Algorithm: "astar", MaxNodes: 2000},
+	} {
+		opts := opts
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 5; i++ {
+				if _, err := Solve(ctx, b, opts); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			if solvable, err := b.IsSolvable(ctx); err != nil || !solvable || b.IsGoal() {
+				t.Error("the board should be solvable and not solved")
+			}
+			if report := b.HeuristicReport(); report.Total <= 0 || report.Total > b.Manhattan()*2 {
+				t.Errorf("heuristic %d out of range (Manhattan Distance %d)", report.Total, b.Manhattan())
+			}
+		}
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		rng := rand.New(rand.NewSource(1))
+		opts := RandomOptions{Method: RandomWalk, WalkLength: 20}
+		for i := 0; i < 5; i++ {
+			if _, err := GenerateRandom(ctx, rng, opts, 2); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+}
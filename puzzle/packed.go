@@ -0,0 +1,103 @@
+package puzzle
+
+// PackedState is a compact representation of State: 4 bits per tile in a uint64, where
+// cell i (row i/4, column i%4) uses bits 4*i..4*i+3, plus the cached cell of the blank.
+// It is 16 bytes instead of the 128 of State and can be copied and compared cheaply.
+type PackedState struct {
+	tiles uint64
+	blank int8
+}
+
+// noCell marks a move that would take the blank off the board in blankTargets.
+const noCell = -1
+
+// blankTargets[cell][m] is the cell the blank moves to when it is at cell and makes move m,
+// or noCell if the move is not legal there.
+var blankTargets = func() [16][4]int8 {
+	var targets [16][4]int8
+	for cell := 0; cell < 16; cell++ {
+		for m := Up; m <= Right; m++ {
+			i, j := cell/4+moveOffsets[m][0], cell%4+moveOffsets[m][1]
+			if i < 0 || i >= 4 || j < 0 || j >= 4 {
+				targets[cell][m] = noCell
+			} else {
+				targets[cell][m] = int8(i*4 + j)
+			}
+		}
+	}
+	return targets
+}()
+
+// legalMoves[cell] lists, in the order Up, Down, Left, Right, the moves the blank can make from cell.
+var legalMoves = func() [16][]Move {
+	var moves [16][]Move
+	for cell := 0; cell < 16; cell++ {
+		for m := Up; m <= Right; m++ {
+			if blankTargets[cell][m] != noCell {
+				moves[cell] = append(moves[cell], m)
+			}
+		}
+	}
+	return moves
+}()
+
+// packedGoal is the goal state, by default 1..15 with the blank in the bottom right
+// corner; setGoal changes it.
+var packedGoal PackedState
+
+// packState converts a State into its packed representation.
+func packState(state State) PackedState {
+	var p PackedState
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			cell := i*4 + j
+			p.tiles |= uint64(state[i][j]) << uint(4*cell)
+			if state[i][j] == 0 {
+				p.blank = int8(cell)
+			}
+		}
+	}
+	return p
+}
+
+// unpack converts a PackedState back into a State.
+func (p PackedState) unpack() State {
+	var state State
+	for cell := 0; cell < 16; cell++ {
+		state[cell/4][cell%4] = p.tile(cell)
+	}
+	return state
+}
+
+// tile returns the tile at a cell (0 for the blank).
+func (p PackedState) tile(cell int) int {
+	return int(p.tiles>>uint(4*cell)) & 0xF
+}
+
+// isGoal reports whether p is the goal state.
+func (p PackedState) isGoal() bool {
+	return p.tiles == packedGoal.tiles
+}
+
+// slide moves the blank with m using the precomputed tables. It returns the new state,
+// the tile that moved and the cell it came from; valid is false if m is not legal.
+func (p PackedState) slide(m Move) (next PackedState, tile int, from int, valid bool) {
+	target := blankTargets[p.blank][m]
+	if target == noCell {
+		return p, 0, noCell, false
+	}
+	from = int(target)
+	tile = p.tile(from)
+	next.tiles = p.tiles&^(0xF<<uint(4*from)) | uint64(tile)<<uint(4*int(p.blank))
+	next.blank = target
+	return next, tile, from, true
+}
+
+// unpackPath converts a path of packed states into States.
+func unpackPath(path []PackedState) []State {
+	states := make([]State, len(path))
+	for i, p := range path {
+		states[i] = p.unpack()
+	}
+	return states
+}
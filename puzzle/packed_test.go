@@ -0,0 +1,74 @@
+package puzzle
+
+import "testing"
+
+func TestPackRoundTrip(t *testing.T) {
+	tests := []string{
+		"1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 0",
+		"0 15 14 13 12 11 10 9 8 7 6 5 4 3 2 1",
+		"5 1 2 4 9 6 3 8 13 10 7 11 14 0 15 12",
+		"1 6 8 2 11 15 7 3 14 13 9 12 5 4 0 10",
+		"15 2 3 4 5 6 7 8 9 10 11 12 13 14 0 1",
+	}
+	for _, test := range tests {
+		b, err := ParseBoard(test, 4, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := packState(b.State())
+		if got := p.unpack(); got != b.State() {
+			t.Errorf("%s: unpacked %v", test, got)
+		}
+		if want := int8(b.blank()); p.blank != want {
+			t.Errorf("%s: blank at %d, want %d", test, p.blank, want)
+		}
+		for cell := 0; cell < 16; cell++ {
+			if p.tile(cell) != b.Tiles[cell] {
+				t.Errorf("%s: tile(%d) = %d, want %d", test, cell, p.tile(cell), b.Tiles[cell])
+			}
+		}
+	}
+}
+
+// TestSlideMatchesBoardMove checks the precomputed move tables against Board.move with the
+// blank in every cell.
+func TestSlideMatchesBoardMove(t *testing.T) {
+	if err := Configure(Options{}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		tiles string
+	}{
+		{"goal", "1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 0"},
+		{"reversed", "15 14 13 12 11 10 9 8 7 6 5 4 3 2 1 0"},
+	}
+	for _, test := range tests {
+		start, err := ParseBoard(test.tiles, 4, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for cell := 0; cell < 16; cell++ {
+			b := Board{Rows: 4, Cols: 4, Tiles: append([]int(nil), start.Tiles...)}
+			b.Tiles[15], b.Tiles[cell] = b.Tiles[cell], b.Tiles[15]
+			p := packState(b.State())
+			for m := Up; m <= Right; m++ {
+				want, ok := b.move(m)
+				next, tile, from, valid := p.slide(m)
+				if valid != ok {
+					t.Errorf("%s, blank at %d, %v: valid %v, want %v", test.name, cell, m, valid, ok)
+					continue
+				}
+				if !ok {
+					continue
+				}
+				if next != packState(want.State()) {
+					t.Errorf("%s, blank at %d, %v: got %v, want %v", test.name, cell, m, next.unpack(), want.State())
+				}
+				if want.Tiles[cell] != tile || want.blank() != from {
+					t.Errorf("%s, blank at %d, %v: moved tile %d from %d, want %d from %d", test.name, cell, m, tile, from, want.Tiles[cell], want.blank())
+				}
+			}
+		}
+	}
+}
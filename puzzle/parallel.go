@@ -0,0 +1,224 @@
+package puzzle
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// minTasksPerWorker is how many frontier subtrees each worker should get on average, so that
+// uneven subtrees still keep every worker busy until the end of the iteration.
+const minTasksPerWorker = 16
+
+// maxFrontierDepth limits how deep the tree below the root is expanded to build the frontier.
+const maxFrontierDepth = 12
+
+// frontierTask is a node at the frontier depth whose subtree is searched by a single worker.
+type frontierTask struct {
+	state    PackedState
+	g        int
+	prevMove Move
+	path     []PackedState
+	h        heuristicComponents
+}
+
+// taskResult is what a worker found in the subtree of a frontierTask.
+type taskResult struct {
+	solved    bool
+	bound     int
+	path      []PackedState
+	generated int
+	expanded  int
+	best      partialPath
+}
+
+// collectFrontier recorre los primeros niveles del árbol igual que search, pero en vez de
+// bajar más allá de depth guarda cada nodo de la frontera como tarea, en el mismo orden
+// en que el solver secuencial los visitaría.
+func collectFrontier(s *searcher, state PackedState, g, bound int, prevMove *Move, statePath []PackedState, h heuristicComponents, depth int, tasks *[]frontierTask) (bool, int, []PackedState) {
+	hValue := h.value(state)
+	s.best.offer(hValue, statePath)
+	f := g + hValue
+	if f > bound {
+		return false, f, nil
+	}
+	if state.isGoal() {
+		return true, bound, statePath
+	}
+	s.expanded++
+	minBound := math.MaxInt32
+	for _, m := range legalMoves[state.blank] {
+		if prevMove != nil && m == opposite(*prevMove) {
+			continue
+		}
+		newState, tile, from, _ := state.slide(m)
+		newH := h.update(newState, tile, from, int(state.blank))
+		if s.limits != nil && s.limits.exceeded(s.generated, &s.reported) {
+			return false, math.MaxInt32, nil
+		}
+		s.generated++
+		newStatePath := append(statePath, newState)
+		if depth == 1 {
+			path := make([]PackedState, len(newStatePath))
+			copy(path, newStatePath)
+			*tasks = append(*tasks, frontierTask{state: newState, g: g + 1, prevMove: m, path: path, h: newH})
+			continue
+		}
+		solved, t, resultPath := collectFrontier(s, newState, g+1, bound, &m, newStatePath, newH, depth-1, tasks)
+		if solved {
+			return true, t, resultPath
+		}
+		if t < minBound {
+			minBound = t
+		}
+	}
+	return false, minBound, nil
+}
+
+// frontierDepth returns the smallest depth whose number of nodes (without pruning) gives
+// every worker enough subtrees to work on.
+func frontierDepth(root State, workers int) int {
+	type node struct {
+		state    PackedState
+		prevMove Move
+	}
+	level := []node{{state: packState(root), prevMove: -1}}
+	for depth := 1; depth <= maxFrontierDepth; depth++ {
+		var next []node
+		for _, n := range level {
+			for _, m := range legalMoves[n.state.blank] {
+				if n.prevMove != -1 && m == opposite(n.prevMove) {
+					continue
+				}
+				newState, _, _, _ := n.state.slide(m)
+				next = append(next, node{state: newState, prevMove: m})
+			}
+		}
+		if len(next) >= workers*minTasksPerWorker {
+			return depth
+		}
+		level = next
+	}
+	return maxFrontierDepth
+}
+
+// parallelIDAStar ejecuta IDA* repartiendo los subárboles de la frontera entre varias
+// goroutines. Cada iteración termina cuando todas las tareas anteriores a la primera
+// solución encontrada han terminado, así que el camino devuelto es el mismo que
+// devolvería idaStar. Los estados generados son la suma de todos los workers.
+func parallelIDAStar(root State, workers int, limits *searchLimits) idaOutcome {
+	start := time.Now()
+	bound := heuristic(root)
+	depth := frontierDepth(root, workers)
+	packedRoot := packState(root)
+	rootH := newHeuristicComponents(packedRoot)
+	total, expanded := 0, 0
+	var best partialPath
+
+	// Each worker owns a transposition table, so they never need to synchronize on it.
+	var tables []*transpositionTable
+	if ttMegabytes > 0 {
+		megabytes := ttMegabytes / workers
+		if megabytes < 1 {
+			megabytes = 1
+		}
+		for w := 0; w < workers; w++ {
+			tables = append(tables, newTranspositionTable(megabytes, ttPolicy))
+		}
+	}
+	for {
+		previous := total
+		shallow := &searcher{limits: limits}
+		var tasks []frontierTask
+		solved, newBound, path := collectFrontier(shallow, packedRoot, 0, bound, nil, []PackedState{packedRoot}, rootH, depth, &tasks)
+		total += shallow.generated
+		expanded += shallow.expanded
+		best.merge(shallow.best)
+		limits.flush(shallow.generated, &shallow.reported)
+		if !solved {
+			results, decided := runFrontierTasks(tasks, bound, workers, tables, limits)
+			for _, result := range results {
+				total += result.generated
+				expanded += result.expanded
+				best.merge(result.best)
+			}
+			for _, result := range results[:decided] {
+				if result.solved {
+					solved, path = true, result.path
+					break
+				}
+				if result.bound < newBound {
+					newBound = result.bound
+				}
+			}
+		}
+
+		generatedStates = total
+		expandedStates = expanded
+		ttStats = transpositionStats{}
+		for _, table := range tables {
+			ttStats.add(table.stats)
+		}
+		if limits.stopped() {
+			return idaOutcome{bound: bound, best: best}
+		}
+		reportIteration(start, bound, newBound, previous, generatedStates)
+		if solved {
+			return idaOutcome{path: unpackPath(path), solved: true, bound: bound, best: best}
+		}
+		if newBound == math.MaxInt32 {
+			return idaOutcome{bound: bound, best: best}
+		}
+		bound = newBound
+	}
+}
+
+// runFrontierTasks searches every task with the given bound using a pool of workers.
+// Once a task finds a solution, the tasks after it are cancelled while the ones before it
+// keep running, because the sequential solver would have found their solutions first.
+// It returns the results of every task, so their nodes are counted even when they were
+// cancelled, and how many of them, from the first, decide the path and the next bound.
+func runFrontierTasks(tasks []frontierTask, bound, workers int, tables []*transpositionTable, limits *searchLimits) ([]taskResult, int) {
+	results := make([]taskResult, len(tasks))
+	found := int64(math.MaxInt64)
+	next := int64(-1)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		var table *transpositionTable
+		if tables != nil {
+			table = tables[w]
+		}
+		go func() {
+			defer wg.Done()
+			for {
+				i := atomic.AddInt64(&next, 1)
+				if i >= int64(len(tasks)) || i > atomic.LoadInt64(&found) {
+					return
+				}
+				task := tasks[i]
+				s := &searcher{task: i, found: &found, table: table, limits: limits}
+				solved, t, path := s.search(task.state, task.g, bound, &task.prevMove, task.path, task.h)
+				limits.flush(s.generated, &s.reported)
+				results[i] = taskResult{solved: solved, bound: t, path: path, generated: s.generated, expanded: s.expanded, best: s.best}
+				if solved {
+					for {
+						current := atomic.LoadInt64(&found)
+						if i >= current || atomic.CompareAndSwapInt64(&found, current, i) {
+							break
+						}
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Tasks after the first solution may not have run at all; they must not lower the bound.
+	if found != math.MaxInt64 {
+		return results, int(found) + 1
+	}
+	return results, len(results)
+}
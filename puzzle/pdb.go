@@ -0,0 +1,334 @@
+package puzzle
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// patternPartitions lists the supported additive disjoint partitions of the tiles 1..15.
+// Every tile belongs to exactly one pattern, so the sum of the pattern costs is admissible.
+var patternPartitions = map[string][][]int{
+	"6-6-3": {
+		{1, 5, 6, 9, 10, 13},
+		{7, 8, 11, 12, 14, 15},
+		{2, 3, 4},
+	},
+	"5-5-5": {
+		{1, 5, 6, 9, 10},
+		{2, 3, 4, 7, 8},
+		{11, 12, 13, 14, 15},
+	},
+}
+
+// DefaultPartition is the partition of the pdb heuristic when Options.Partition is empty.
+const DefaultPartition = "6-6-3"
+
+// maxPatterns is the largest number of patterns in a partition.
+const maxPatterns = 3
+
+// PatternDatabase holds the minimum number of moves of the pattern tiles needed to bring
+// them to their goal positions, indexed by the positions those tiles occupy.
+type PatternDatabase struct {
+	Tiles []int
+	Table []uint8
+}
+
+var (
+	// patternCache holds the pattern databases of the selected partition.
+	patternCache []PatternDatabase
+	// loadPatternsOnce ensures that patternCache is loaded only once.
+	loadPatternsOnce sync.Once
+	// loadPatternsErr is the error of the first load, returned to every later caller.
+	loadPatternsErr error
+	// patternOfTile maps each tile to the index of its pattern in patternCache and its
+	// position inside that pattern.
+	patternOfTile [16][2]int
+)
+
+// blankNeighbors lists, for each cell of the board, the cells the blank can move to.
+var blankNeighbors = func() [16][]int {
+	var neighbors [16][]int
+	for cell := 0; cell < 16; cell++ {
+		i, j := cell/4, cell%4
+		for _, offset := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			ni, nj := i+offset[0], j+offset[1]
+			if ni >= 0 && ni < 4 && nj >= 0 && nj < 4 {
+				neighbors[cell] = append(neighbors[cell], ni*4+nj)
+			}
+		}
+	}
+	return neighbors
+}()
+
+// patternSize returns the number of entries of a pattern database with k tiles (16!/(16-k)!).
+func patternSize(k int) int {
+	size := 1
+	for i := 0; i < k; i++ {
+		size *= 16 - i
+	}
+	return size
+}
+
+// patternRank converts the cells occupied by the pattern tiles into a dense table index.
+// Each tile contributes the rank of its cell among the cells not used by the previous tiles.
+func patternRank(positions []int) int {
+	index := 0
+	for i, pos := range positions {
+		digit := pos
+		for _, prev := range positions[:i] {
+			if prev < pos {
+				digit--
+			}
+		}
+		index = index*(16-i) + digit
+	}
+	return index
+}
+
+// patternUnrank is the inverse of patternRank: it fills positions from a table index.
+func patternUnrank(index int, positions []int) {
+	k := len(positions)
+	digits := make([]int, k)
+	for i := k - 1; i >= 0; i-- {
+		digits[i] = index % (16 - i)
+		index /= 16 - i
+	}
+	used := 0
+	for i, digit := range digits {
+		for cell := 0; cell < 16; cell++ {
+			if used&(1<<cell) != 0 {
+				continue
+			}
+			if digit == 0 {
+				positions[i] = cell
+				used |= 1 << cell
+				break
+			}
+			digit--
+		}
+	}
+}
+
+// buildPatternDatabase runs a backward breadth-first search from the goal (see setGoal) over the abstract
+// states (pattern tile cells, blank cell). Moving the blank over a tile outside the pattern
+// is free; moving a pattern tile costs one move. The table keeps, for each placement of the
+// pattern tiles, the cheapest cost over all blank cells.
+func buildPatternDatabase(tiles []int) PatternDatabase {
+	k := len(tiles)
+	size := patternSize(k)
+	table := make([]uint8, size)
+	for i := range table {
+		table[i] = 0xFF
+	}
+
+	// visited and queued are bitsets over idx*16+blank.
+	visited := make([]uint64, size*16/64+1)
+	queued := make([]uint64, size*16/64+1)
+	isSet := func(bits []uint64, s uint32) bool { return bits[s/64]&(1<<(s%64)) != 0 }
+	set := func(bits []uint64, s uint32) { bits[s/64] |= 1 << (s % 64) }
+
+	goal := make([]int, k)
+	for i, tile := range tiles {
+		goal[i] = goalCells[tile]
+	}
+	next := []uint32{uint32(patternRank(goal)*16 + goalCells[0])}
+	set(queued, next[0])
+
+	positions := make([]int, k)
+	moved := make([]int, k)
+	for cost := 0; len(next) > 0; cost++ {
+		current := next
+		next = nil
+		for head := 0; head < len(current); head++ {
+			s := current[head]
+			if isSet(visited, s) {
+				continue
+			}
+			set(visited, s)
+			index, blank := int(s/16), int(s%16)
+			if table[index] == 0xFF {
+				table[index] = uint8(cost)
+			}
+
+			patternUnrank(index, positions)
+			for _, cell := range blankNeighbors[blank] {
+				tile := -1
+				for i, pos := range positions {
+					if pos == cell {
+						tile = i
+						break
+					}
+				}
+				if tile == -1 {
+					// Free move: the blank swaps with a tile outside the pattern.
+					neighbor := uint32(index*16 + cell)
+					if !isSet(visited, neighbor) {
+						current = append(current, neighbor)
+					}
+					continue
+				}
+				copy(moved, positions)
+				moved[tile] = blank
+				neighbor := uint32(patternRank(moved)*16 + cell)
+				if !isSet(visited, neighbor) && !isSet(queued, neighbor) {
+					set(queued, neighbor)
+					next = append(next, neighbor)
+				}
+			}
+		}
+	}
+
+	return PatternDatabase{Tiles: tiles, Table: table}
+}
+
+// patternFileName returns the name of the table of the pattern database number i of a
+// partition. The databases depend on the goal, so a goal other than the standard one adds
+// its key to the name.
+func patternFileName(partition string, i int) string {
+	if key := goalFor(4, 4).key(); key != "" {
+		return fmt.Sprintf("pdb_%s_%s_%d.bin", partition, key, i)
+	}
+	return fmt.Sprintf("pdb_%s_%d.bin", partition, i)
+}
+
+// Partitions returns the names of the supported pattern database partitions.
+func Partitions() []string {
+	names := make([]string, 0, len(patternPartitions))
+	for name := range patternPartitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GeneratePatternDatabases builds and saves the pattern databases of a partition next to the
+// walking distance table, skipping the ones that have already been generated.
+func GeneratePatternDatabases(partition string) error {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return generatePatternDatabases(partition)
+}
+
+// generatePatternDatabases is GeneratePatternDatabases without the lock.
+func generatePatternDatabases(partition string) error {
+	patterns, ok := patternPartitions[partition]
+	if !ok {
+		return fmt.Errorf("unknown partition %q", partition)
+	}
+
+	for i, tiles := range patterns {
+		name := patternFileName(partition, i)
+		if tables.has(name) {
+			continue
+		}
+		if tables.NoGenerate {
+			return tables.missing("la base de patrones", name)
+		}
+		logf("Generando la base de patrones %v en %s...\n", tiles, tables.path(name))
+		pdb := buildPatternDatabase(tiles)
+		if err := tables.store(name, encodeTable(tablePatternDatabase, 4, 4, pdb.Table)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadPatternDatabases reads the pattern databases of a partition and caches them in patternCache.
+func loadPatternDatabases(partition string) error {
+	patterns, ok := patternPartitions[partition]
+	if !ok {
+		return fmt.Errorf("unknown partition %q", partition)
+	}
+
+	databases := make([]PatternDatabase, 0, len(patterns))
+	for i, tiles := range patterns {
+		name := patternFileName(partition, i)
+		data, err := tables.readAny(name)
+		if err != nil {
+			return fmt.Errorf("error reading file: %v", err)
+		}
+		if len(data) == patternSize(len(tiles)) {
+			// Files written before the binary container hold only the entries, with nothing
+			// to check that they belong to this pattern and goal.
+			return fmt.Errorf("pattern database %s has no header; convert it with: solver convert-table %s %s",
+				tables.path(name), tables.path(name), tables.path(name))
+		}
+		data, err = decodeTable(data, tablePatternDatabase, 4, 4, patternSize(len(tiles)))
+		if err != nil {
+			return fmt.Errorf("pattern database %s: %v", tables.path(name), err)
+		}
+		databases = append(databases, PatternDatabase{Tiles: tiles, Table: data})
+		for k, tile := range tiles {
+			patternOfTile[tile] = [2]int{i, k}
+		}
+	}
+
+	patternCache = databases
+	return nil
+}
+
+// loadPatternTables loads the pattern databases of pdbPartition once for the current
+// Tables, goal and partition (see resetTables).
+func loadPatternTables() error {
+	loadPatternsOnce.Do(func() {
+		loadPatternsErr = loadPatternDatabases(pdbPartition)
+	})
+	return loadPatternsErr
+}
+
+// PatternDatabaseHeuristic adds up the costs of every pattern of the selected partition.
+// Since the patterns are disjoint and each one only counts the moves of its own tiles,
+// the sum never overestimates the real distance. It fails if the databases cannot be
+// loaded.
+func PatternDatabaseHeuristic(state [4][4]int) (int, error) {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return patternDatabaseHeuristic(state)
+}
+
+// patternDatabaseHeuristic is PatternDatabaseHeuristic without the lock.
+func patternDatabaseHeuristic(state [4][4]int) (int, error) {
+	if err := loadPatternTables(); err != nil {
+		return 0, fmt.Errorf("error loading pattern databases: %w", err)
+	}
+	return patternDatabaseValue(state), nil
+}
+
+// patternDatabaseValue is PatternDatabaseHeuristic once the databases are loaded (see
+// loadHeuristicTables).
+func patternDatabaseValue(state [4][4]int) int {
+	var cells [16]int
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			cells[state[i][j]] = i*4 + j
+		}
+	}
+
+	total := 0
+	positions := make([]int, 0, 8)
+	for _, pdb := range patternCache {
+		positions = positions[:0]
+		for _, tile := range pdb.Tiles {
+			positions = append(positions, cells[tile])
+		}
+		total += int(pdb.Table[patternRank(positions)])
+	}
+	return total
+}
+
+// loadHeuristicTables loads the table of the 4x4 heuristic of the configuration: the
+// pattern databases of pdbPartition or the walking distance table. The searches on 4x4
+// states check it first, so they never run with a missing table as a heuristic of 0.
+func loadHeuristicTables() error {
+	if pdbPartition != "" {
+		if err := loadPatternTables(); err != nil {
+			return fmt.Errorf("error loading pattern databases: %w", err)
+		}
+		return nil
+	}
+	if err := loadWalkingTable(); err != nil {
+		return fmt.Errorf("error loading walking distance table: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package puzzle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestPatternRankRoundTrip(t *testing.T) {
+	for k := 1; k <= 3; k++ {
+		positions := make([]int, k)
+		seen := make(map[int]bool)
+		for index := 0; index < patternSize(k); index++ {
+			patternUnrank(index, positions)
+			for i, pos := range positions {
+				for _, prev := range positions[:i] {
+					if pos == prev {
+						t.Fatalf("patternUnrank(%d) = %v repeats a cell", index, positions)
+					}
+				}
+			}
+			if got := patternRank(positions); got != index {
+				t.Fatalf("patternRank(%v) = %d, want %d", positions, got, index)
+			}
+			seen[index] = true
+		}
+		if len(seen) != patternSize(k) {
+			t.Errorf("%d tiles: %d indexes, want %d", k, len(seen), patternSize(k))
+		}
+	}
+
+	tests := [][]int{
+		{0, 1, 2, 3, 4, 5},
+		{15, 14, 13, 12, 11, 10},
+		{3, 9, 0, 15, 6, 12, 7},
+	}
+	for _, positions := range tests {
+		index := patternRank(positions)
+		if index < 0 || index >= patternSize(len(positions)) {
+			t.Errorf("patternRank(%v) = %d, out of range", positions, index)
+		}
+		got := make([]int, len(positions))
+		patternUnrank(index, got)
+		for i := range got {
+			if got[i] != positions[i] {
+				t.Errorf("patternUnrank(patternRank(%v)) = %v", positions, got)
+				break
+			}
+		}
+	}
+}
+
+func TestPatternDatabaseLoadError(t *testing.T) {
+	files := fstest.MapFS{}
+	for i := range patternPartitions["5-5-5"] {
+		files[patternFileName("5-5-5", i)] = &fstest.MapFile{Data: []byte("garbage")}
+	}
+	opts := Options{Heuristic: HeuristicPDB, Partition: "5-5-5", Tables: &Tables{FS: files}}
+	b, err := ParseBoard("5 1 2 4 9 6 3 8 13 10 7 11 14 0 15 12", 4, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Solve(context.Background(), b, opts); err == nil || !strings.Contains(err.Error(), "pattern database") {
+		t.Errorf("Solve: err = %v, want a pattern database error", err)
+	}
+	if _, err := PatternDatabaseHeuristic(b.State()); err == nil {
+		t.Error("PatternDatabaseHeuristic: no error")
+	}
+	// Without PrepareTables the search must not run with a heuristic of 0.
+	if err := Configure(opts); err != nil {
+		t.Fatal(err)
+	}
+	if result := SolverIDAStar(context.Background(), b.State()); result.Solved || !strings.Contains(result.Stopped, "pattern database") {
+		t.Errorf("SolverIDAStar: Solved %v, Stopped %q", result.Solved, result.Stopped)
+	}
+}
+
+// TestLegacyPatternDatabase checks that a pattern database without the container header is
+// rejected until convert-table adds the header.
+func TestLegacyPatternDatabase(t *testing.T) {
+	if err := Configure(Options{}); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for i, tiles := range patternPartitions["5-5-5"] {
+		entries := make([]byte, patternSize(len(tiles)))
+		if err := os.WriteFile(filepath.Join(dir, patternFileName("5-5-5", i)), entries, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dirTables := DirTables(dir)
+	dirTables.NoGenerate = true
+	opts := Options{Heuristic: HeuristicPDB, Partition: "5-5-5", Tables: dirTables}
+	if err := Configure(opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadPatternDatabases("5-5-5"); err == nil || !strings.Contains(err.Error(), "convert-table") {
+		t.Fatalf("loadPatternDatabases: err = %v, want a hint to convert-table", err)
+	}
+
+	for i := range patternPartitions["5-5-5"] {
+		name := filepath.Join(dir, patternFileName("5-5-5", i))
+		if err := ConvertTable(name, name); err != nil {
+			t.Fatal(err)
+		}
+		if err := ConvertTable(name, name); err == nil {
+			t.Errorf("ConvertTable converted %s twice", name)
+		}
+	}
+	if err := loadPatternDatabases("5-5-5"); err != nil {
+		t.Errorf("loadPatternDatabases after convert-table: %v", err)
+	}
+}
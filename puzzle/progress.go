@@ -0,0 +1,62 @@
+package puzzle
+
+import "time"
+
+// ProgressEvent describes one finished IDA* iteration.
+type ProgressEvent struct {
+	Bound     int `json:"bound"`
+	NextBound int `json:"next_bound"`
+	// Nodes is the number of states generated by this iteration, TotalNodes since the start.
+	Nodes          int           `json:"nodes"`
+	TotalNodes     int           `json:"total_nodes"`
+	Elapsed        time.Duration `json:"-"`
+	ElapsedMs      float64       `json:"elapsed_ms"`
+	NodesPerSecond float64       `json:"nodes_per_sec"`
+}
+
+// ProgressFunc receives the progress events of a search. It is called synchronously from
+// the goroutine that runs the search, between iterations, while the search holds the
+// package lock: it must not call back into the package.
+type ProgressFunc func(ProgressEvent)
+
+// progressFunc receives the events of every IDA* search (nil discards them).
+var progressFunc ProgressFunc
+
+// reportIteration records a finished IDA* iteration in iterations and sends its event to
+// progressFunc. previous is the total of generated states when the iteration started.
+func reportIteration(start time.Time, bound, nextBound, previous, total int) {
+	iterations = append(iterations, IterationInfo{Bound: bound, NextBound: nextBound, Generated: total})
+	if progressFunc == nil {
+		return
+	}
+	elapsed := time.Since(start)
+	event := ProgressEvent{
+		Bound:      bound,
+		NextBound:  nextBound,
+		Nodes:      total - previous,
+		TotalNodes: total,
+		Elapsed:    elapsed,
+		ElapsedMs:  float64(elapsed) / float64(time.Millisecond),
+	}
+	if elapsed > 0 {
+		event.NodesPerSecond = float64(total) / elapsed.Seconds()
+	}
+	progressFunc(event)
+}
+
+// Improvement describes a solution found by the anytime solver that is shorter than the
+// previous ones.
+type Improvement struct {
+	Length    int           `json:"length"`
+	Generated int           `json:"generated"`
+	Elapsed   time.Duration `json:"-"`
+	ElapsedMs float64       `json:"elapsed_ms"`
+}
+
+// ImprovementFunc receives the solutions of the anytime solver as they are found. It is
+// called synchronously from the goroutine that runs the search, under the package lock
+// like ProgressFunc.
+type ImprovementFunc func(Improvement)
+
+// improvementFunc receives the improvements of every anytime search (nil discards them).
+var improvementFunc ImprovementFunc
@@ -0,0 +1,56 @@
+package puzzle
+
+import (
+	"context"
+	"testing"
+)
+
+// TestProgressEvents checks that the progress callback gets one event per IDA* iteration
+// with the same bounds and node counts as Result.Iterations.
+func TestProgressEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       Options
+		rows, cols int
+		board      string
+	}{
+		{name: "sequential", opts: Options{Heuristic: HeuristicAdmissible}},
+		{name: "parallel", opts: Options{Heuristic: HeuristicAdmissible, Workers: 4}},
+		{name: "3x3", rows: 3, cols: 3, board: "8 6 7 2 5 4 3 0 1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, cols, board := 4, 4, idaInstances[1].board
+			if test.board != "" {
+				rows, cols, board = test.rows, test.cols, test.board
+			}
+			var events []ProgressEvent
+			opts := test.opts
+			opts.Progress = func(e ProgressEvent) { events = append(events, e) }
+			result, err := Solve(context.Background(), mustBoard(t, board, rows, cols), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !result.Solved || len(events) == 0 || len(events) != len(result.Iterations) {
+				t.Fatalf("solved %v, %d events, %d iterations", result.Solved, len(events), len(result.Iterations))
+			}
+			total := 0
+			for i, e := range events {
+				iteration := result.Iterations[i]
+				total += e.Nodes
+				if e.Bound != iteration.Bound || e.NextBound != iteration.NextBound || e.TotalNodes != iteration.Generated || e.TotalNodes != total {
+					t.Errorf("event %d = %+v, iteration %+v, %d nodes so far", i, e, iteration, total)
+				}
+				if i > 0 && (e.Bound != events[i-1].NextBound || e.Elapsed < events[i-1].Elapsed) {
+					t.Errorf("event %d = %+v follows %+v", i, e, events[i-1])
+				}
+				if e.ElapsedMs < 0 || e.NodesPerSecond < 0 {
+					t.Errorf("event %d = %+v", i, e)
+				}
+			}
+			if last := events[len(events)-1]; last.TotalNodes != result.Generated || last.Bound != result.Length {
+				t.Errorf("last event %+v, generated %d, length %d", last, result.Generated, result.Length)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package puzzle
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var extraHeuristic = false
+
+// optimalMode restringe la búsqueda a heurísticas admisibles para garantizar soluciones óptimas.
+var optimalMode = false
+
+// pdbPartition es la partición de bases de patrones aditivas usada como heurística ("" si no se usa).
+var pdbPartition = ""
+
+// workers es el número de goroutines del IDA* paralelo (0 o 1 ejecuta el solver secuencial).
+var workers = 0
+
+// ttMegabytes es la memoria de la tabla de transposición en MB (0 la desactiva).
+var ttMegabytes = 0
+
+// ttPolicy es la política de reemplazo de la tabla de transposición.
+var ttPolicy = replaceShallow
+
+// moveMetric es la métrica con la que se cuentan los movimientos (MetricSingle o MetricMulti).
+var moveMetric = MetricSingle
+
+// algorithm es el algoritmo de búsqueda elegido con Options.Algorithm.
+var algorithm = "idastar"
+
+// astarWeight es el peso de la heurística en el A* ponderado.
+var astarWeight = 2.0
+
+// maxStates es el máximo de estados que A*, A* ponderado y BFS guardan en memoria.
+var maxStates = DefaultMaxStates
+
+// timeLimit es el tiempo disponible para el solver anytime (0 sin límite).
+var timeLimit time.Duration
+
+// searchTimeout es el tiempo máximo de cualquier búsqueda (0 sin límite).
+var searchTimeout time.Duration
+
+// maxNodes es el máximo de estados que puede generar una búsqueda (0 sin límite).
+var maxNodes = 0
+
+// logOutput recibe los mensajes sobre las tablas y las soluciones del solver anytime (nil los descarta).
+var logOutput io.Writer
+
+// logf escribe un mensaje en logOutput.
+func logf(format string, args ...interface{}) {
+	if logOutput != nil {
+		fmt.Fprintf(logOutput, format, args...)
+	}
+}
+
+// countInversions cuenta el número de inversiones en el puzzle (ignorando el espacio vacío 0).
+func countInversions(puzzle []int) int {
+	inversions := 0
+	for i := 0; i < len(puzzle); i++ {
+		if puzzle[i] == 0 {
+			continue
+		}
+		for j := i + 1; j < len(puzzle); j++ {
+			if puzzle[j] != 0 && puzzle[i] > puzzle[j] {
+				inversions++
+			}
+		}
+	}
+	return inversions
+}
+
+// findBlankPosition encuentra la fila donde está el espacio vacío (contando desde abajo, 1-indexed)
+// en un tablero de rows filas y cols columnas.
+func findBlankPosition(puzzle []int, rows, cols int) int {
+	blankIndex := -1
+
+	for i, value := range puzzle {
+		if value == 0 {
+			blankIndex = i
+			break
+		}
+	}
+
+	// Calcula la fila desde abajo
+	return rows - (blankIndex / cols)
+}
+
+// isSolvable verifica si el objetivo actual del 15-puzzle se puede alcanzar desde el estado.
+func isSolvable(state State) bool {
+	n := 4 // Tamaño de la cuadrícula (4x4)
+	var puzzle []int
+
+	// Convertir la matriz 4x4 en una lista lineal
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			puzzle = append(puzzle, state[i][j])
+		}
+	}
+	return goalFor(n, n).reachable(puzzle)
+}
+
+// isSolvableTiles verifica si un tablero de rows filas y cols columnas, escrito fila por
+// fila, puede llegar al objetivo estándar (el espacio vacío en la última casilla).
+func isSolvableTiles(puzzle []int, rows, cols int) bool {
+	inversions := countInversions(puzzle)
+	blankRow := findBlankPosition(puzzle, rows, cols)
+
+	// Aplicando la regla de solvencia: lo que importa es el número de columnas
+	if cols%2 != 0 {
+		// Si el ancho de la cuadrícula es IMPAR (como 3x3), el puzzle es resoluble si el número de inversiones es par
+		return inversions%2 == 0
+	} else {
+		// Si el ancho de la cuadrícula es PAR (como 4x4)
+		if blankRow%2 == 0 {
+			// Si el espacio vacío está en una fila PAR desde abajo, el número de inversiones debe ser IMPAR
+			return inversions%2 != 0
+		} else {
+			// Si el espacio vacío está en una fila IMPAR desde abajo, el número de inversiones debe ser PAR
+			return inversions%2 == 0
+		}
+	}
+}
+
+// ParseState convierte una línea con 16 números separados por espacios en un estado y
+// verifica con ValidateState que sea una permutación de 0..15.
+func ParseState(input string) (State, error) {
+	var initial State
+	fields := strings.Fields(input)
+	if len(fields) != 16 {
+		return initial, fmt.Errorf("Debe ingresar 16 números")
+	}
+	for idx, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return initial, fmt.Errorf("Error al convertir: %s", field)
+		}
+		initial[idx/4][idx%4] = n
+	}
+	if err := ValidateState(initial); err != nil {
+		return initial, err
+	}
+	return initial, nil
+}
@@ -0,0 +1,197 @@
+package puzzle
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+)
+
+// Generation methods accepted by random -method.
+const (
+	RandomUniform = "uniform"
+	RandomWalk    = "walk"
+)
+
+// DefaultRandomAttempts is how many candidates GenerateRandom tries per requested puzzle
+// before giving up on filters that are too strict.
+const DefaultRandomAttempts = 10000
+
+// RandomOptions configures GenerateRandom. A zero Max* field means no upper limit.
+type RandomOptions struct {
+	// Method is RandomUniform or RandomWalk.
+	Method string
+	// WalkLength is the number of moves of each random walk.
+	WalkLength int
+	// MinH and MaxH keep the puzzles whose heuristic is in the range.
+	MinH, MaxH int
+	// MinDistance and MaxDistance keep the puzzles whose optimal solution length, verified
+	// with IDA* and an admissible heuristic, is in the range.
+	MinDistance, MaxDistance int
+	// Attempts is the number of candidates tried per puzzle (DefaultRandomAttempts if 0).
+	Attempts int
+	// Rows and Cols are the size of the boards (4x4 if 0).
+	Rows, Cols int
+}
+
+// RandomPuzzle is a generated puzzle with its heuristic and, if it was verified, its
+// optimal distance (-1 otherwise).
+type RandomPuzzle struct {
+	State    Board `json:"state"`
+	H        int   `json:"h"`
+	Distance int   `json:"distance"`
+}
+
+// VerifiesDistance reports whether the options need the optimal distance of each candidate.
+func (o RandomOptions) VerifiesDistance() bool {
+	return o.MinDistance > 0 || o.MaxDistance > 0
+}
+
+// size returns the board size of the options.
+func (o RandomOptions) size() (rows, cols int) {
+	if o.Rows == 0 || o.Cols == 0 {
+		return 4, 4
+	}
+	return o.Rows, o.Cols
+}
+
+// randomSolvableBoard baraja las fichas hasta obtener un tablero resoluble, así cada
+// tablero resoluble tiene la misma probabilidad. Con paredes que no deciden la búsqueda
+// (ErrUndecided) devuelve el error: no se puede sortear entre los tableros resolubles.
+func randomSolvableBoard(ctx context.Context, rng *rand.Rand, rows, cols int) (Board, error) {
+	o := obstaclesFor(rows, cols)
+	for {
+		b := Board{Rows: rows, Cols: cols, Tiles: rng.Perm(rows * cols)}
+		if o != nil {
+			// Las casillas bloqueadas conservan su ficha: solo se barajan las libres.
+			goal := goalFor(rows, cols)
+			var free []int
+			for cell := range goal.Tiles {
+				if !o.blocked[cell] {
+					free = append(free, cell)
+				}
+			}
+			b.Tiles = append([]int(nil), goal.Tiles...)
+			for i, k := range rng.Perm(len(free)) {
+				b.Tiles[free[i]] = goal.Tiles[free[k]]
+			}
+		}
+		solvable, err := b.isSolvable(ctx)
+		if err != nil {
+			return Board{}, err
+		}
+		if solvable {
+			return b, nil
+		}
+	}
+}
+
+// randomWalkBoard aplica length movimientos al azar desde el objetivo actual sin deshacer nunca
+// el movimiento anterior (como opposite en la búsqueda), salvo cuando las paredes o las
+// casillas bloqueadas no dejan otra salida.
+func randomWalkBoard(rng *rand.Rand, rows, cols, length int) Board {
+	goal := goalFor(rows, cols)
+	b := Board{Rows: rows, Cols: cols, Tiles: append([]int(nil), goal.Tiles...)}
+	prev := Move(-1)
+	for i := 0; i < length; i++ {
+		var candidates []Board
+		var candidateMoves []Move
+		for m := Up; m <= Right; m++ {
+			if prev != -1 && m == opposite(prev) {
+				continue
+			}
+			if next, ok := b.move(m); ok {
+				candidates = append(candidates, next)
+				candidateMoves = append(candidateMoves, m)
+			}
+		}
+		if len(candidates) == 0 {
+			if prev == -1 {
+				break // el espacio vacío no se puede mover
+			}
+			prev, i = -1, i-1
+			continue
+		}
+		k := rng.Intn(len(candidates))
+		b, prev = candidates[k], candidateMoves[k]
+	}
+	return b
+}
+
+// GenerateRandom generates count puzzles with rng following opts. The heuristic filter
+// uses the heuristic of Configure; the distance filter solves each candidate with IDA*, so
+// it needs an admissible heuristic and the tables loaded with PrepareTables. It fails if a
+// puzzle cannot be found within the attempts, if ctx ends or if the uniform method cannot
+// decide whether a candidate is solvable (ErrUndecided).
+func GenerateRandom(ctx context.Context, rng *rand.Rand, opts RandomOptions, count int) ([]RandomPuzzle, error) {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return generateRandom(ctx, rng, opts, count)
+}
+
+// generateRandom is GenerateRandom without the lock.
+func generateRandom(ctx context.Context, rng *rand.Rand, opts RandomOptions, count int) ([]RandomPuzzle, error) {
+	if opts.Method != RandomUniform && opts.Method != RandomWalk {
+		return nil, fmt.Errorf("método desconocido: %s (opciones: uniform, walk)", opts.Method)
+	}
+	rows, cols := opts.size()
+	if opts.VerifiesDistance() && rows == 4 && cols == 4 && !heuristicAdmissible() {
+		return nil, fmt.Errorf("el filtro de distancia necesita una heurística admisible (admissible o pdb)")
+	}
+	attempts := opts.Attempts
+	if attempts == 0 {
+		attempts = DefaultRandomAttempts
+	}
+
+	puzzles := make([]RandomPuzzle, 0, count)
+	for len(puzzles) < count {
+		found := false
+		for attempt := 0; attempt < attempts && !found; attempt++ {
+			if err := ctx.Err(); err != nil {
+				return puzzles, err
+			}
+			var b Board
+			if opts.Method == RandomWalk {
+				b = randomWalkBoard(rng, rows, cols, opts.WalkLength)
+			} else {
+				var err error
+				if b, err = randomSolvableBoard(ctx, rng, rows, cols); err != nil {
+					return puzzles, err
+				}
+			}
+			puzzle, ok := filterRandom(ctx, b, opts)
+			if ok {
+				puzzles = append(puzzles, puzzle)
+				found = true
+			}
+		}
+		if !found {
+			return puzzles, fmt.Errorf("no se encontró un puzzle que cumpla los filtros en %d intentos", attempts)
+		}
+	}
+	return puzzles, nil
+}
+
+// filterRandom computes the heuristic (and the distance if needed) of a candidate and
+// reports whether it passes the filters of opts.
+func filterRandom(ctx context.Context, b Board, opts RandomOptions) (RandomPuzzle, bool) {
+	puzzle := RandomPuzzle{State: b, H: b.heuristic(), Distance: -1}
+	if puzzle.H < opts.MinH || (opts.MaxH > 0 && puzzle.H > opts.MaxH) {
+		return puzzle, false
+	}
+	if !opts.VerifiesDistance() {
+		return puzzle, true
+	}
+	// The heuristic is admissible, so it already rules out puzzles that are too far.
+	if opts.MaxDistance > 0 && puzzle.H > opts.MaxDistance {
+		return puzzle, false
+	}
+	result := solveBoard(ctx, b, "idastar")
+	if !result.Solved {
+		return puzzle, false
+	}
+	puzzle.Distance = result.Length
+	if puzzle.Distance < opts.MinDistance || (opts.MaxDistance > 0 && puzzle.Distance > opts.MaxDistance) {
+		return puzzle, false
+	}
+	return puzzle, true
+}
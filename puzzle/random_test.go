@@ -0,0 +1,102 @@
+package puzzle
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateRandom(t *testing.T) {
+	tests := []struct {
+		name string
+		opts RandomOptions
+	}{
+		{"uniform", RandomOptions{Method: RandomUniform}},
+		{"uniform heuristic range", RandomOptions{Method: RandomUniform, MinH: 40, MaxH: 44}},
+		{"walk", RandomOptions{Method: RandomWalk, WalkLength: 30}},
+		{"walk distance range", RandomOptions{Method: RandomWalk, WalkLength: 24, MinDistance: 14, MaxDistance: 18}},
+		{"walk 3x3", RandomOptions{Method: RandomWalk, WalkLength: 15, Rows: 3, Cols: 3}},
+		{"uniform 3x3 distance range", RandomOptions{Method: RandomUniform, Rows: 3, Cols: 3, MinDistance: 20, MaxDistance: 22}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, cols := test.opts.size()
+			if err := Configure(Options{Heuristic: HeuristicAdmissible}); err != nil {
+				t.Fatal(err)
+			}
+			if err := PrepareTables(rows, cols); err != nil {
+				t.Fatal(err)
+			}
+			puzzles, err := GenerateRandom(context.Background(), rand.New(rand.NewSource(7)), test.opts, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := GenerateRandom(context.Background(), rand.New(rand.NewSource(7)), test.opts, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(puzzles, again) {
+				t.Errorf("the same seed generated different puzzles")
+			}
+			if len(puzzles) != 5 {
+				t.Fatalf("%d puzzles, want 5", len(puzzles))
+			}
+			for _, p := range puzzles {
+				b := p.State
+				solvable, err := b.IsSolvable(context.Background())
+				if b.Rows != rows || b.Cols != cols || b.Validate() != nil || err != nil || !solvable {
+					t.Errorf("%v: invalid or unsolvable %dx%d board", b, b.Rows, b.Cols)
+				}
+				if p.H != b.Heuristic() || p.H < test.opts.MinH || test.opts.MaxH > 0 && p.H > test.opts.MaxH {
+					t.Errorf("%v: h = %d", b, p.H)
+				}
+				if !test.opts.VerifiesDistance() {
+					if p.Distance != -1 {
+						t.Errorf("%v: distance %d without a distance filter", b, p.Distance)
+					}
+				} else if p.Distance < test.opts.MinDistance || p.Distance > test.opts.MaxDistance {
+					t.Errorf("%v: distance %d out of range", b, p.Distance)
+				}
+			}
+			if test.opts.Method != RandomWalk {
+				return
+			}
+			for _, p := range puzzles {
+				b := p.State
+				// Every move changes the parity of the distance, and the walk never needs
+				// more moves than it made.
+				result, err := Solve(context.Background(), b, Options{Heuristic: HeuristicAdmissible})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.Length > test.opts.WalkLength || (test.opts.WalkLength-result.Length)%2 != 0 {
+					t.Errorf("%v: distance %d after a walk of %d moves", b, result.Length, test.opts.WalkLength)
+				}
+				if p.Distance != -1 && p.Distance != result.Length {
+					t.Errorf("%v: distance %d, solved in %d", b, p.Distance, result.Length)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateRandomErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		heuristic string
+		opts      RandomOptions
+	}{
+		{"unknown method", HeuristicAdmissible, RandomOptions{Method: "shuffle"}},
+		{"distance without an admissible heuristic", HeuristicDefault, RandomOptions{Method: RandomUniform, MaxDistance: 40}},
+		{"filters too strict", HeuristicAdmissible, RandomOptions{Method: RandomWalk, WalkLength: 10, MinH: 20, Attempts: 50}},
+	}
+	for _, test := range tests {
+		if err := Configure(Options{Heuristic: test.heuristic}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := GenerateRandom(context.Background(), rand.New(rand.NewSource(1)), test.opts, 1); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
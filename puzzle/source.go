@@ -0,0 +1,116 @@
+package puzzle
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrMissingTable is returned (wrapped) when a table is not found and Tables.NoGenerate
+// forbids generating it.
+var ErrMissingTable = errors.New("la generación está desactivada")
+
+// Tables is where the solver reads its heuristic tables (walking distance and pattern
+// databases) and where it keeps the ones it generates. A table is looked up in the tables
+// generated by this value, then in FS and then in Fallback.
+type Tables struct {
+	// FS holds the tables that already exist (nil if none), for example os.DirFS(dir).
+	FS fs.FS
+	// Fallback is read when a table is not in FS, for example the tables embedded in a binary.
+	Fallback fs.FS
+	// Dir is the directory where the generated tables are written. With "" they are only
+	// kept in memory.
+	Dir string
+	// NoGenerate makes a missing table an error (ErrMissingTable) instead of generating it.
+	NoGenerate bool
+
+	mu        sync.Mutex
+	generated map[string][]byte
+}
+
+// DirTables returns the Tables of a directory: the tables are read from dir and the
+// missing ones are generated into it.
+func DirTables(dir string) *Tables {
+	return &Tables{FS: os.DirFS(dir), Dir: dir}
+}
+
+// tables is the source of the tables of the current configuration (see Configure).
+var tables = &Tables{}
+
+// path returns the name of a table as shown in the messages.
+func (t *Tables) path(name string) string {
+	if t.Dir != "" {
+		return filepath.Join(t.Dir, name)
+	}
+	return name
+}
+
+// has reports whether the table was generated or is in FS.
+func (t *Tables) has(name string) bool {
+	t.mu.Lock()
+	_, ok := t.generated[name]
+	t.mu.Unlock()
+	if ok {
+		return true
+	}
+	return t.FS != nil && statFS(t.FS, name)
+}
+
+// hasFallback reports whether the table is in Fallback.
+func (t *Tables) hasFallback(name string) bool {
+	return t.Fallback != nil && statFS(t.Fallback, name)
+}
+
+// statFS reports whether name exists in fsys.
+func statFS(fsys fs.FS, name string) bool {
+	_, err := fs.Stat(fsys, name)
+	return err == nil
+}
+
+// read returns a generated table or the one in FS, without looking at Fallback.
+func (t *Tables) read(name string) ([]byte, error) {
+	t.mu.Lock()
+	data, ok := t.generated[name]
+	t.mu.Unlock()
+	if ok {
+		return data, nil
+	}
+	if t.FS == nil {
+		return nil, fmt.Errorf("open %s: %w", name, fs.ErrNotExist)
+	}
+	return fs.ReadFile(t.FS, name)
+}
+
+// readAny is read followed by Fallback.
+func (t *Tables) readAny(name string) ([]byte, error) {
+	data, err := t.read(name)
+	if errors.Is(err, fs.ErrNotExist) && t.hasFallback(name) {
+		return fs.ReadFile(t.Fallback, name)
+	}
+	return data, err
+}
+
+// store keeps a generated table and writes it into Dir, if set.
+func (t *Tables) store(name string, data []byte) error {
+	t.mu.Lock()
+	if t.generated == nil {
+		t.generated = make(map[string][]byte)
+	}
+	t.generated[name] = data
+	t.mu.Unlock()
+	if t.Dir == "" {
+		return nil
+	}
+	if err := os.WriteFile(t.path(name), data, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", t.path(name), err)
+	}
+	return nil
+}
+
+// missing returns the error of a table that is not found when generation is disabled.
+func (t *Tables) missing(what, name string) error {
+	return fmt.Errorf("no se encontró %s %s y %w", what, t.path(name), ErrMissingTable)
+}
@@ -0,0 +1,329 @@
+package puzzle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Binary table container. Every heuristic table is stored as a fixed header followed by
+// one byte per entry, addressed by a dense index computed from the state (see
+// walkingIndex and patternRank). All integers are little endian.
+const (
+	tableMagic   = "15PT"
+	tableVersion = 1
+	// tableUnreachable marks the entries of indices that no reachable state maps to.
+	tableUnreachable = 0xFF
+)
+
+// Kinds of table stored in the container.
+const (
+	tableWalkingDistance      uint8 = 1
+	tablePatternDatabase      uint8 = 2
+	tableWalkingDistanceMulti uint8 = 3
+)
+
+// tableHeader is the header written at the start of every binary table.
+type tableHeader struct {
+	Magic    [4]byte
+	Version  uint16
+	Rows     uint8
+	Cols     uint8
+	Kind     uint8
+	_        [3]byte
+	Entries  uint64
+	Checksum uint32 // CRC-32 (IEEE) of the entries
+}
+
+// tableKindName returns a readable name of a table kind for error messages.
+func tableKindName(kind uint8) string {
+	switch kind {
+	case tableWalkingDistance:
+		return "walking distance"
+	case tablePatternDatabase:
+		return "pattern database"
+	case tableWalkingDistanceMulti:
+		return "multi-tile walking distance"
+	}
+	return "kind " + strconv.Itoa(int(kind))
+}
+
+// encodeTable returns the binary container of a table of the given kind for a rows x cols
+// board (for a walking distance table, the lines and the width of its axis).
+func encodeTable(kind uint8, rows, cols int, entries []byte) []byte {
+	header := tableHeader{
+		Version:  tableVersion,
+		Rows:     uint8(rows),
+		Cols:     uint8(cols),
+		Kind:     kind,
+		Entries:  uint64(len(entries)),
+		Checksum: crc32.ChecksumIEEE(entries),
+	}
+	copy(header.Magic[:], tableMagic)
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, header)
+	buf.Write(entries)
+	return buf.Bytes()
+}
+
+// decodeTable checks the header of a binary table against the expected kind, board size
+// and number of entries and returns the entries.
+func decodeTable(data []byte, kind uint8, rows, cols, entries int) ([]byte, error) {
+	var header tableHeader
+	size := binary.Size(header)
+	if len(data) < size || string(data[:4]) != tableMagic {
+		return nil, fmt.Errorf("not a binary table (bad magic)")
+	}
+	binary.Read(bytes.NewReader(data[:size]), binary.LittleEndian, &header)
+	switch {
+	case header.Version != tableVersion:
+		return nil, fmt.Errorf("unsupported table version %d (expected %d)", header.Version, tableVersion)
+	case int(header.Rows) != rows || int(header.Cols) != cols:
+		return nil, fmt.Errorf("table is for a %dx%d board (expected %dx%d)", header.Rows, header.Cols, rows, cols)
+	case header.Kind != kind:
+		return nil, fmt.Errorf("table holds a %s, expected a %s", tableKindName(header.Kind), tableKindName(kind))
+	case header.Entries != uint64(entries):
+		return nil, fmt.Errorf("table has %d entries, expected %d", header.Entries, entries)
+	case uint64(len(data)-size) != header.Entries:
+		return nil, fmt.Errorf("table is truncated: %d of %d entries", len(data)-size, header.Entries)
+	}
+	body := data[size:]
+	if crc32.ChecksumIEEE(body) != header.Checksum {
+		return nil, fmt.Errorf("table checksum mismatch")
+	}
+	return body, nil
+}
+
+// Dense index of the walking distance table. A walking distance state is a 4x4 matrix of
+// counts packed as in countShift: row r holds how many tiles of each goal row (or column)
+// are in row r. Every row adds up to 4 except the row of the blank, which adds up to 3,
+// and the columns add up to 4, 4, 4 and 3, so the last row is implied by the others. The
+// index is the blank row followed by the rank of the first three rows among the rows with
+// the same sum (35 compositions of 4 into four parts, 20 of 3).
+const (
+	wdRowCodes     = 1 << 12 // 4 counts of 3 bits
+	wdRowRanks     = 35
+	walkingEntries = 4 * wdRowRanks * wdRowRanks * wdRowRanks
+)
+
+var (
+	// wdRowSum and wdRowRank give the sum and the rank of a packed row (-1 if a count is above 4).
+	wdRowSum  [wdRowCodes]int8
+	wdRowRank [wdRowCodes]int16
+	// wdRowsBySum lists the packed rows with each sum in rank order.
+	wdRowsBySum [5][]uint16
+)
+
+func init() {
+	for code := 0; code < wdRowCodes; code++ {
+		sum := 0
+		valid := true
+		for group := 0; group < 4; group++ {
+			count := code >> uint(3*group) & 7
+			valid = valid && count <= 4
+			sum += count
+		}
+		wdRowSum[code] = int8(sum)
+		wdRowRank[code] = -1
+		if valid && sum <= 4 {
+			wdRowRank[code] = int16(len(wdRowsBySum[sum]))
+			wdRowsBySum[sum] = append(wdRowsBySum[sum], uint16(code))
+		}
+	}
+}
+
+// walkingIndex returns the dense index of a packed count matrix of a reachable state.
+func walkingIndex(counts uint64) int {
+	row0 := counts & (wdRowCodes - 1)
+	row1 := counts >> 12 & (wdRowCodes - 1)
+	row2 := counts >> 24 & (wdRowCodes - 1)
+	blank := 3
+	switch {
+	case wdRowSum[row0] == 3:
+		blank = 0
+	case wdRowSum[row1] == 3:
+		blank = 1
+	case wdRowSum[row2] == 3:
+		blank = 2
+	}
+	return ((blank*wdRowRanks+int(wdRowRank[row0]))*wdRowRanks+int(wdRowRank[row1]))*wdRowRanks + int(wdRowRank[row2])
+}
+
+// validWalkingIndex returns the index of counts and whether counts is a valid walking
+// distance state, for lookups of matrices that do not come from the search.
+func validWalkingIndex(counts uint64) (int, bool) {
+	index := walkingIndex(counts)
+	if index < 0 || index >= walkingEntries {
+		return 0, false
+	}
+	back, ok := walkingCounts(index)
+	return index, ok && back == counts
+}
+
+// walkingCounts is the inverse of walkingIndex; ok is false if no valid matrix has that index.
+func walkingCounts(index int) (counts uint64, ok bool) {
+	blank := index / (wdRowRanks * wdRowRanks * wdRowRanks)
+	ranks := [3]int{
+		index / (wdRowRanks * wdRowRanks) % wdRowRanks,
+		index / wdRowRanks % wdRowRanks,
+		index % wdRowRanks,
+	}
+	columns := [4]int{4, 4, 4, 3}
+	for row, rank := range ranks {
+		sum := 4
+		if row == blank {
+			sum = 3
+		}
+		if rank >= len(wdRowsBySum[sum]) {
+			return 0, false
+		}
+		code := uint64(wdRowsBySum[sum][rank])
+		counts |= code << uint(12*row)
+		for group := range columns {
+			columns[group] -= int(code >> uint(3*group) & 7)
+		}
+	}
+	sum := 0
+	for group, count := range columns {
+		if count < 0 {
+			return 0, false
+		}
+		sum += count
+		counts |= uint64(count) << countShift(3, group)
+	}
+	if (blank == 3) != (sum == 3) {
+		return 0, false
+	}
+	return counts, true
+}
+
+// packCounts packs a 4x4 count matrix (as written in the JSON table) as in countShift.
+func packCounts(matrix [][]int) uint64 {
+	var packed uint64
+	for row := range matrix {
+		for group, count := range matrix[row] {
+			packed |= uint64(count) << countShift(row, group)
+		}
+	}
+	return packed
+}
+
+// countsKey returns the JSON key ("a,b,...") of a packed count matrix.
+func countsKey(counts uint64) string {
+	parts := make([]string, 16)
+	for cell := range parts {
+		parts[cell] = strconv.Itoa(int(counts >> uint(3*cell) & 7))
+	}
+	return strings.Join(parts, ",")
+}
+
+// walkingTableFromJSON converts the map of the JSON walking distance table into dense entries.
+func walkingTableFromJSON(states map[string]int) ([]byte, error) {
+	entries := bytes.Repeat([]byte{tableUnreachable}, walkingEntries)
+	for key, distance := range states {
+		parts := strings.Split(key, ",")
+		if len(parts) != 16 {
+			return nil, fmt.Errorf("invalid key %q", key)
+		}
+		matrix := make([][]int, 4)
+		for row := range matrix {
+			matrix[row] = make([]int, 4)
+			for group := range matrix[row] {
+				count, err := strconv.Atoi(parts[row*4+group])
+				if err != nil || count < 0 || count > 4 {
+					return nil, fmt.Errorf("invalid key %q", key)
+				}
+				matrix[row][group] = count
+			}
+		}
+		index, ok := validWalkingIndex(packCounts(matrix))
+		if !ok {
+			return nil, fmt.Errorf("key %q is not a walking distance state", key)
+		}
+		if distance < 0 || distance >= tableUnreachable {
+			return nil, fmt.Errorf("invalid distance for %q: %d", key, distance)
+		}
+		entries[index] = byte(distance)
+	}
+	return entries, nil
+}
+
+// walkingTableToJSON converts dense walking distance entries into the map of the JSON table.
+func walkingTableToJSON(entries []byte) map[string]int {
+	states := make(map[string]int)
+	for index, distance := range entries {
+		if distance == tableUnreachable {
+			continue
+		}
+		if counts, ok := walkingCounts(index); ok {
+			states[countsKey(counts)] = int(distance)
+		}
+	}
+	return states
+}
+
+// ConvertTable converts a walking distance table file between the JSON format (.json) and
+// the binary format (any other extension); the direction follows the extension of in. A
+// pattern database of the standard goal written before the binary container (only the
+// entries, named as pdb_6-6-3_0.bin) is converted into the container instead.
+func ConvertTable(in, out string) error {
+	if tiles, ok := legacyPatternTiles(in); ok {
+		data, err := os.ReadFile(in)
+		if err != nil {
+			return fmt.Errorf("error reading file: %v", err)
+		}
+		if _, err := decodeTable(data, tablePatternDatabase, 4, 4, patternSize(len(tiles))); err == nil {
+			return fmt.Errorf("%s already has a header", in)
+		}
+		if len(data) != patternSize(len(tiles)) {
+			return fmt.Errorf("%s: it is not a pattern database without header of %d entries", in, patternSize(len(tiles)))
+		}
+		if err := os.WriteFile(out, encodeTable(tablePatternDatabase, 4, 4, data), 0644); err != nil {
+			return fmt.Errorf("error writing %s: %w", out, err)
+		}
+		return nil
+	}
+	if strings.HasSuffix(in, ".json") {
+		data, err := os.ReadFile(in)
+		if err != nil {
+			return fmt.Errorf("error reading file: %v", err)
+		}
+		entries, err := decodeWalkingJSON(data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", in, err)
+		}
+		if err := os.WriteFile(out, encodeTable(tableWalkingDistance, 4, 4, entries), 0644); err != nil {
+			return fmt.Errorf("error writing %s: %w", out, err)
+		}
+		return nil
+	}
+
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return fmt.Errorf("error reading file: %v", err)
+	}
+	entries, err := decodeTable(data, tableWalkingDistance, 4, 4, walkingEntries)
+	if err != nil {
+		return fmt.Errorf("%s: %v", in, err)
+	}
+	return saveResults(walkingTableToJSON(entries), out)
+}
+
+// legacyPatternTiles returns the tiles of the pattern a file is named after, if its name is
+// that of a pattern database of the standard goal.
+func legacyPatternTiles(file string) ([]int, bool) {
+	for partition, patterns := range patternPartitions {
+		for i, tiles := range patterns {
+			if filepath.Base(file) == fmt.Sprintf("pdb_%s_%d.bin", partition, i) {
+				return tiles, true
+			}
+		}
+	}
+	return nil, false
+}
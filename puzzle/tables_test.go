@@ -0,0 +1,100 @@
+package puzzle
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTableRoundTrip(t *testing.T) {
+	tests := []struct {
+		kind       uint8
+		rows, cols int
+		entries    []byte
+	}{
+		{tableWalkingDistance, 4, 4, []byte{0, 1, 2, tableUnreachable, 3}},
+		{tablePatternDatabase, 4, 4, bytes.Repeat([]byte{7}, 1000)},
+		{tableWalkingDistanceMulti, 3, 5, []byte{}},
+	}
+	for _, test := range tests {
+		data := encodeTable(test.kind, test.rows, test.cols, test.entries)
+		entries, err := decodeTable(data, test.kind, test.rows, test.cols, len(test.entries))
+		if err != nil {
+			t.Errorf("%s: %v", tableKindName(test.kind), err)
+		} else if !bytes.Equal(entries, test.entries) {
+			t.Errorf("%s: entries differ after the round trip", tableKindName(test.kind))
+		}
+	}
+}
+
+func TestDecodeTableErrors(t *testing.T) {
+	entries := []byte{0, 1, 2, 3, 4, 5, 6, 7}
+	tests := []struct {
+		name    string
+		corrupt func(data []byte) []byte
+		want    string
+	}{
+		{"checksum", func(data []byte) []byte { data[len(data)-1] ^= 1; return data }, "checksum mismatch"},
+		{"checksum field", func(data []byte) []byte { data[20] ^= 1; return data }, "checksum mismatch"},
+		{"magic", func(data []byte) []byte { data[0] = 'X'; return data }, "bad magic"},
+		{"short", func(data []byte) []byte { return data[:10] }, "bad magic"},
+		{"version", func(data []byte) []byte { data[4] = 9; return data }, "version"},
+		{"size", func(data []byte) []byte { data[6] = 3; return data }, "3x4 board"},
+		{"kind", func(data []byte) []byte { data[8] = tableWalkingDistance; return data }, "walking distance"},
+		{"truncated", func(data []byte) []byte { return data[:len(data)-1] }, "truncated"},
+	}
+	for _, test := range tests {
+		data := test.corrupt(encodeTable(tablePatternDatabase, 4, 4, entries))
+		if _, err := decodeTable(data, tablePatternDatabase, 4, 4, len(entries)); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: err = %v, want %q", test.name, err, test.want)
+		}
+	}
+	if _, err := decodeTable(encodeTable(tablePatternDatabase, 4, 4, entries), tablePatternDatabase, 4, 4, 9); err == nil {
+		t.Error("entries: no error")
+	}
+}
+
+func TestTablesFileRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	entries := []byte{5, 4, 3, 2, 1}
+	if err := DirTables(dir).store("test.bin", encodeTable(tablePatternDatabase, 4, 4, entries)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := DirTables(dir).read("test.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := decodeTable(data, tablePatternDatabase, 4, 4, len(entries)); err != nil || !bytes.Equal(got, entries) {
+		t.Errorf("read back %v, %v", got, err)
+	}
+}
+
+// A walking distance table whose entries were changed on disk must be rejected when it
+// is loaded, instead of guiding the search with wrong values.
+func TestCorruptWalkingTableRejected(t *testing.T) {
+	dir := t.TempDir()
+	if err := Configure(Options{Tables: DirTables(dir)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := PrepareTables(4, 4); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, walkingDistanceFile)
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 1
+	if err := os.WriteFile(name, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Configure(Options{Tables: DirTables(dir)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := PrepareTables(4, 4); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("PrepareTables: err = %v, want a checksum mismatch", err)
+	}
+}
@@ -0,0 +1,101 @@
+package puzzle
+
+import "unsafe"
+
+// Replacement policies of the transposition table when two states fall in the same slot.
+const (
+	// replaceAlways overwrites the slot with the most recent state.
+	replaceAlways = "always"
+	// replaceShallow keeps the state reached with the smallest g in the current iteration,
+	// since its subtree covers the subtrees of deeper visits.
+	replaceShallow = "shallow"
+)
+
+// DefaultTTMegabytes is the memory used by the transposition table when -tt is given without a value in the CLI.
+const DefaultTTMegabytes = 64
+
+// ttEntry stores the best g seen for a state during the iteration with the given bound.
+type ttEntry struct {
+	key   uint64
+	g     uint16
+	bound uint16
+	used  bool
+}
+
+// transpositionStats counts how the transposition table answered the lookups.
+type transpositionStats struct {
+	hits, misses, stores, replacements int
+}
+
+// transpositionTable is a fixed-size hash table of ttEntry used by IDA* to avoid expanding
+// again a state already expanded in the same iteration with a smaller or equal g.
+type transpositionTable struct {
+	entries []ttEntry
+	mask    uint64
+	policy  string
+	stats   transpositionStats
+}
+
+// newTranspositionTable creates a table that uses at most megabytes of memory. The number
+// of slots is rounded down to a power of two so the slot can be taken with a mask.
+func newTranspositionTable(megabytes int, policy string) *transpositionTable {
+	slots := uint64(megabytes) << 20 / uint64(unsafe.Sizeof(ttEntry{}))
+	size := uint64(1)
+	for size*2 <= slots {
+		size *= 2
+	}
+	return &transpositionTable{entries: make([]ttEntry, size), mask: size - 1, policy: policy}
+}
+
+// hashState mixes the bits of a packed state (splitmix64 finalizer) so that similar boards
+// are spread over the whole table.
+func hashState(tiles uint64) uint64 {
+	tiles ^= tiles >> 30
+	tiles *= 0xbf58476d1ce4e5b9
+	tiles ^= tiles >> 27
+	tiles *= 0x94d049bb133111eb
+	tiles ^= tiles >> 31
+	return tiles
+}
+
+// visit reports whether the state was already expanded in the iteration with this bound
+// with a g lower or equal than the current one, in which case its subtree can be pruned.
+// Otherwise it records the visit according to the replacement policy.
+func (t *transpositionTable) visit(state PackedState, g, bound int) bool {
+	entry := &t.entries[hashState(state.tiles)&t.mask]
+	if entry.used && entry.key == state.tiles && int(entry.bound) == bound {
+		if int(entry.g) <= g {
+			t.stats.hits++
+			return true
+		}
+		t.stats.misses++
+		entry.g = uint16(g)
+		return false
+	}
+	t.stats.misses++
+
+	if entry.used && entry.key != state.tiles && t.policy == replaceShallow &&
+		int(entry.bound) == bound && int(entry.g) < g {
+		return false
+	}
+	if entry.used {
+		t.stats.replacements++
+	}
+	t.stats.stores++
+	*entry = ttEntry{key: state.tiles, g: uint16(g), bound: uint16(bound), used: true}
+	return false
+}
+
+// add accumulates the statistics of another table, used to report the parallel workers together.
+func (s *transpositionStats) add(other transpositionStats) {
+	s.hits += other.hits
+	s.misses += other.misses
+	s.stores += other.stores
+	s.replacements += other.replacements
+}
+
+// logTranspositionStats escribe las estadísticas de la tabla de transposición con logf.
+func logTranspositionStats(stats transpositionStats) {
+	logf("Tabla de transposición: aciertos %d, fallos %d, guardados %d, reemplazos %d\n",
+		stats.hits, stats.misses, stats.stores, stats.replacements)
+}
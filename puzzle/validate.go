@@ -0,0 +1,79 @@
+package puzzle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// InvalidStateError describes why a board is not a permutation of 0..Cells-1.
+type InvalidStateError struct {
+	// Cells is the number of cells of the board (16 for the 15-puzzle).
+	Cells int
+	// Missing are the values of 0..Cells-1 that do not appear on the board.
+	Missing []int
+	// Duplicated are the values of 0..Cells-1 that appear more than once, in increasing order.
+	Duplicated []int
+	// OutOfRange are the values outside 0..Cells-1, in board order.
+	OutOfRange []int
+}
+
+func (e *InvalidStateError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "faltan "+joinInts(e.Missing))
+	}
+	if len(e.Duplicated) > 0 {
+		parts = append(parts, "repetidos "+joinInts(e.Duplicated))
+	}
+	if len(e.OutOfRange) > 0 {
+		parts = append(parts, "fuera de rango "+joinInts(e.OutOfRange))
+	}
+	return fmt.Sprintf("El puzzle debe contener los números del 0 al %d una sola vez: ", e.Cells-1) + strings.Join(parts, "; ")
+}
+
+// joinInts writes a list of values separated by commas.
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ValidateState checks that the board is a permutation of 0..15 and returns an
+// *InvalidStateError listing the missing, duplicated and out-of-range values otherwise.
+// Every entry point (ParseState, Solve, VerifyMoves, VerifyStates) calls it, since the
+// solvers and move assume exactly one blank and one copy of each tile.
+func ValidateState(state State) error {
+	tiles := make([]int, 0, 16)
+	for _, row := range state {
+		tiles = append(tiles, row[:]...)
+	}
+	return validateTiles(tiles)
+}
+
+// validateTiles checks that tiles is a permutation of 0..len(tiles)-1.
+func validateTiles(tiles []int) error {
+	counts := make([]int, len(tiles))
+	err := InvalidStateError{Cells: len(tiles)}
+	for _, v := range tiles {
+		if v < 0 || v >= len(tiles) {
+			err.OutOfRange = append(err.OutOfRange, v)
+			continue
+		}
+		counts[v]++
+	}
+	for v, count := range counts {
+		switch {
+		case count == 0:
+			err.Missing = append(err.Missing, v)
+		case count > 1:
+			err.Duplicated = append(err.Duplicated, v)
+		}
+	}
+	if err.Missing == nil && err.Duplicated == nil && err.OutOfRange == nil {
+		return nil
+	}
+	return &err
+}
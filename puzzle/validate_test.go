@@ -0,0 +1,72 @@
+package puzzle
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateState(t *testing.T) {
+	tests := []struct {
+		name       string
+		tiles      []int
+		missing    []int
+		duplicated []int
+		outOfRange []int
+	}{
+		{name: "valid", tiles: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 0}},
+		{name: "duplicated", tiles: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 14, 0},
+			missing: []int{15}, duplicated: []int{14}},
+		{name: "two blanks", tiles: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 0, 15, 0},
+			missing: []int{14}, duplicated: []int{0}},
+		{name: "out of range", tiles: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 16, 0},
+			missing: []int{15}, outOfRange: []int{16}},
+		{name: "negative", tiles: []int{-1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 0},
+			missing: []int{1}, outOfRange: []int{-1}},
+		{name: "everything", tiles: []int{20, 2, 2, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 17, 0},
+			missing: []int{1, 3, 15}, duplicated: []int{2}, outOfRange: []int{20, 17}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var state State
+			for i, v := range test.tiles {
+				state[i/4][i%4] = v
+			}
+			err := ValidateState(state)
+			if test.missing == nil && test.duplicated == nil && test.outOfRange == nil {
+				if err != nil {
+					t.Fatalf("ValidateState: %v", err)
+				}
+				return
+			}
+			var invalid *InvalidStateError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("ValidateState: err = %v, want an *InvalidStateError", err)
+			}
+			if invalid.Cells != 16 || !reflect.DeepEqual(invalid.Missing, test.missing) ||
+				!reflect.DeepEqual(invalid.Duplicated, test.duplicated) || !reflect.DeepEqual(invalid.OutOfRange, test.outOfRange) {
+				t.Errorf("got %+v", *invalid)
+			}
+		})
+	}
+}
+
+func TestParseBoardErrors(t *testing.T) {
+	tests := []struct {
+		input      string
+		rows, cols int
+		valid      bool
+	}{
+		{"1 2 3 4 5 6 7 8 0", 3, 3, true},
+		{"1 2 3 4 5 6 7 8", 3, 3, false},
+		{"1 2 3 4 5 6 7 8 x", 3, 3, false},
+		{"1 2 3 4 5 6 7 8 9", 3, 3, false},
+		{"1 2 0", 1, 3, false},
+	}
+	for _, test := range tests {
+		_, err := ParseBoard(test.input, test.rows, test.cols)
+		if (err == nil) != test.valid {
+			t.Errorf("ParseBoard(%q, %d, %d): err = %v", test.input, test.rows, test.cols, err)
+		}
+	}
+}
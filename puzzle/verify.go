@@ -0,0 +1,228 @@
+package puzzle
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// VerifyReport is the outcome of replaying a solution through move.
+type VerifyReport struct {
+	// Length is the number of single-tile moves that were replayed successfully.
+	Length int
+	// Moves is Length counted in the metric of the configuration (see MoveCount).
+	Moves int
+	// Valid is false if a board was invalid, a move was illegal or two consecutive boards
+	// were not one move apart.
+	Valid bool
+	// ErrorStep is the 1-based index of the first invalid move, transition or board of a
+	// list (0 if Valid or if the start board of VerifyBoardMoves is invalid).
+	ErrorStep int
+	Error     string
+	// ReachesGoal reports whether the last board reached is the goal.
+	ReachesGoal bool
+	// FinalBoard is the last board reached and Final the same board as a State when it
+	// is 4x4.
+	FinalBoard Board
+	Final      State
+}
+
+// finish fills the fields of the report that depend on the moves replayed.
+func (r *VerifyReport) finish(moves []Move) {
+	r.Length = len(moves)
+	r.Moves = MoveCount(moves, moveMetric)
+	r.ReachesGoal = r.FinalBoard.isGoal()
+	if r.FinalBoard.is4x4() {
+		r.Final = r.FinalBoard.State()
+	}
+}
+
+// ParseMoves reads a compact move string such as "RDDLUR" written in the given notation
+// and returns the moves of the blank. Spaces and commas are ignored and letters may be
+// lower case.
+func ParseMoves(text, notation string) ([]Move, error) {
+	var moves []Move
+	runes := []rune(strings.ToUpper(text))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == ' ' || r == ',' || r == '\t' {
+			continue
+		}
+		m := Move(-1)
+		for candidate, letter := range moveLetters {
+			if rune(letter) == r {
+				m = Move(candidate)
+			}
+		}
+		if m == -1 {
+			return nil, fmt.Errorf("carácter %q inválido en la posición %d (se esperaba U, D, L o R)", r, i+1)
+		}
+		if notation == NotationTile {
+			m = opposite(m)
+		}
+		// A count after the letter repeats the move, as FormatMetricMoves writes them.
+		count, digits := 0, 0
+		for i+1 < len(runes) && runes[i+1] >= '0' && runes[i+1] <= '9' {
+			count = count*10 + int(runes[i+1]-'0')
+			digits++
+			i++
+		}
+		if digits == 0 {
+			count = 1
+		} else if count == 0 || count > maxBoardSide {
+			return nil, fmt.Errorf("cantidad inválida después de %c en la posición %d", r, i-digits+1)
+		}
+		for ; count > 0; count-- {
+			moves = append(moves, m)
+		}
+	}
+	return moves, nil
+}
+
+// VerifyMoves is VerifyBoardMoves for a 4x4 state.
+func VerifyMoves(start State, moves []Move) VerifyReport {
+	return VerifyBoardMoves(boardFromState(start), moves)
+}
+
+// VerifyBoardMoves replays moves of the blank from start and reports the first illegal
+// move, including the moves blocked by the obstacles of the configuration.
+func VerifyBoardMoves(start Board, moves []Move) VerifyReport {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return verifyBoardMoves(start, moves)
+}
+
+// verifyBoardMoves is VerifyBoardMoves without the lock.
+func verifyBoardMoves(start Board, moves []Move) VerifyReport {
+	report := VerifyReport{Valid: true, FinalBoard: start}
+	if err := checkVerifyBoard(start); err != nil {
+		report.Valid, report.Error = false, err.Error()
+		return report
+	}
+	for i, m := range moves {
+		next, valid := report.FinalBoard.move(m)
+		if !valid {
+			report.Valid = false
+			report.ErrorStep = i + 1
+			report.Error = fmt.Sprintf("el movimiento %d (%s) saca el espacio vacío del tablero", i+1, m)
+			if report.FinalBoard.inside(m) {
+				report.Error = fmt.Sprintf("el movimiento %d (%s) entra en una casilla bloqueada o cruza una pared", i+1, m)
+			}
+			report.finish(moves[:i])
+			return report
+		}
+		report.FinalBoard = next
+	}
+	report.finish(moves)
+	return report
+}
+
+// VerifyStates is VerifyBoardStates for 4x4 states.
+func VerifyStates(states []State) VerifyReport {
+	return VerifyBoardStates(boardsFromStates(states))
+}
+
+// VerifyBoardStates checks that every board of a list is reached from the previous one
+// with a single legal move and reports the first invalid board or transition. In
+// MetricMulti a move may slide several tiles of a row or column.
+func VerifyBoardStates(boards []Board) VerifyReport {
+	solveMu.Lock()
+	defer solveMu.Unlock()
+	return verifyBoardStates(boards)
+}
+
+// verifyBoardStates is VerifyBoardStates without the lock.
+func verifyBoardStates(boards []Board) VerifyReport {
+	if len(boards) == 0 {
+		return VerifyReport{Valid: false, Error: "la lista de estados está vacía"}
+	}
+	for i, b := range boards {
+		err := checkVerifyBoard(b)
+		if err == nil && (b.Rows != boards[0].Rows || b.Cols != boards[0].Cols) {
+			err = fmt.Errorf("el tablero es %dx%d y el primero %dx%d", b.Rows, b.Cols, boards[0].Rows, boards[0].Cols)
+		}
+		if err != nil {
+			return VerifyReport{Valid: false, ErrorStep: i + 1, Error: fmt.Sprintf("estado %d: %v", i+1, err), FinalBoard: boards[0]}
+		}
+	}
+	report := VerifyReport{Valid: true, FinalBoard: boards[0]}
+	var moves []Move
+	for i := 1; i < len(boards); i++ {
+		slide, ok := slideBetween(boards[i-1], boards[i])
+		if !ok {
+			report.Valid = false
+			report.ErrorStep = i
+			report.Error = fmt.Sprintf("el estado %d no se obtiene del estado %d con un solo movimiento", i+1, i)
+			break
+		}
+		moves = append(moves, slide...)
+		report.FinalBoard = boards[i]
+	}
+	report.finish(moves)
+	return report
+}
+
+// checkVerifyBoard checks that b is a valid board for the obstacles of the configuration.
+func checkVerifyBoard(b Board) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	return b.checkObstacles()
+}
+
+// slideBetween returns the single-tile moves of one move that takes from to to: a single
+// move in MetricSingle, or several equal moves in MetricMulti.
+func slideBetween(from, to Board) ([]Move, bool) {
+	for m := Up; m <= Right; m++ {
+		var slide []Move
+		for current, ok := from.move(m); ok; current, ok = current.move(m) {
+			slide = append(slide, m)
+			if equalTiles(current.Tiles, to.Tiles) {
+				return slide, true
+			}
+			if moveMetric != MetricMulti {
+				break
+			}
+		}
+	}
+	return nil, false
+}
+
+// ReadStates is ReadBoards for 4x4 states.
+func ReadStates(fileName string) ([]State, error) {
+	boards, err := ReadBoards(fileName, 4, 4)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]State, len(boards))
+	for i, b := range boards {
+		states[i] = b.State()
+	}
+	return states, nil
+}
+
+// ReadBoards reads one rows x cols board per line from a file, skipping empty lines and
+// '#' comments.
+func ReadBoards(fileName string, rows, cols int) ([]Board, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %w", fileName, err)
+	}
+	defer file.Close()
+
+	var boards []Board
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		b, err := ParseBoard(line, rows, cols)
+		if err != nil {
+			return nil, fmt.Errorf("línea %d: %v", number, err)
+		}
+		boards = append(boards, b)
+	}
+	return boards, scanner.Err()
+}
@@ -0,0 +1,160 @@
+package puzzle
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func mustBoard(t *testing.T, text string, rows, cols int) Board {
+	t.Helper()
+	b, err := ParseBoard(text, rows, cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestVerifyMoves(t *testing.T) {
+	const start = "5 1 2 4 9 6 3 8 13 10 7 11 14 0 15 12"
+	tests := []struct {
+		name        string
+		opts        Options
+		rows, cols  int
+		start       string
+		moves       string
+		valid       bool
+		errorStep   int
+		length      int
+		moveCount   int
+		reachesGoal bool
+	}{
+		{name: "solution", rows: 4, cols: 4, start: start, moves: "LUUURRDDRD",
+			valid: true, length: 10, moveCount: 10, reachesGoal: true},
+		{name: "short", rows: 4, cols: 4, start: start, moves: "LUU",
+			valid: true, length: 3, moveCount: 3},
+		{name: "off the board", rows: 4, cols: 4, start: start, moves: "LUUUU",
+			errorStep: 5, length: 4, moveCount: 4},
+		{name: "first move", rows: 4, cols: 4, start: start, moves: "DLU",
+			errorStep: 1},
+		{name: "invalid start", rows: 4, cols: 4, start: "5 1 2 4 9 6 3 8 13 10 7 11 14 0 15 15", moves: "L"},
+		{name: "multi", opts: Options{Metric: MetricMulti}, rows: 4, cols: 4, start: start, moves: "LU3R2D2RD",
+			valid: true, length: 10, moveCount: 6, reachesGoal: true},
+		{name: "3x3", rows: 3, cols: 3, start: "1 2 3 4 5 6 0 7 8", moves: "RR",
+			valid: true, length: 2, moveCount: 2, reachesGoal: true},
+		{name: "3x3 wall", opts: Options{Obstacles: mustObstacles(t, "", "7-8", 3, 3)}, rows: 3, cols: 3,
+			start: "1 2 3 4 5 6 0 7 8", moves: "RR", errorStep: 2, length: 1, moveCount: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := Configure(test.opts); err != nil {
+				t.Fatal(err)
+			}
+			moves, err := ParseMoves(test.moves, NotationBlank)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b := Board{Rows: test.rows, Cols: test.cols}
+			for _, field := range splitInts(t, test.start) {
+				b.Tiles = append(b.Tiles, field)
+			}
+			report := VerifyBoardMoves(b, moves)
+			if report.Valid != test.valid || report.ErrorStep != test.errorStep || report.Length != test.length ||
+				report.Moves != test.moveCount || report.ReachesGoal != test.reachesGoal {
+				t.Errorf("got valid %v, step %d, length %d, moves %d, goal %v (%s)",
+					report.Valid, report.ErrorStep, report.Length, report.Moves, report.ReachesGoal, report.Error)
+			}
+			if test.valid != (report.Error == "") {
+				t.Errorf("Error = %q", report.Error)
+			}
+			if b.is4x4() {
+				if state := VerifyMoves(b.State(), moves); state.ErrorStep != report.ErrorStep || state.Final != report.Final {
+					t.Errorf("VerifyMoves differs from VerifyBoardMoves: %+v", state)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyStates(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      Options
+		boards    []string
+		valid     bool
+		errorStep int
+		length    int
+		moveCount int
+	}{
+		{name: "empty"},
+		{name: "single", boards: []string{"1 2 3 4 5 6 7 8 0"}, valid: true},
+		{name: "solution", boards: []string{"1 2 3 4 5 6 0 7 8", "1 2 3 4 5 6 7 0 8", "1 2 3 4 5 6 7 8 0"},
+			valid: true, length: 2, moveCount: 2},
+		{name: "jump", boards: []string{"1 2 3 4 5 6 0 7 8", "1 2 3 4 5 6 7 8 0"},
+			errorStep: 1},
+		{name: "second transition", boards: []string{"1 2 3 4 5 6 0 7 8", "1 2 3 4 5 6 7 0 8", "1 2 3 4 5 6 7 0 8"},
+			errorStep: 2, length: 1, moveCount: 1},
+		{name: "multi jump", opts: Options{Metric: MetricMulti}, boards: []string{"1 2 3 4 5 6 0 7 8", "1 2 3 4 5 6 7 8 0"},
+			valid: true, length: 2, moveCount: 1},
+		{name: "invalid board", boards: []string{"1 2 3 4 5 6 0 7 8", "1 2 3 4 5 6 7 8 8"},
+			errorStep: 2},
+		{name: "invalid first board", boards: []string{"1 2 3 4 5 6 0 7 9", "1 2 3 4 5 6 7 0 8"},
+			errorStep: 1},
+		{name: "blocked cell", opts: Options{Obstacles: mustObstacles(t, "0", "", 3, 3)},
+			boards: []string{"1 2 3 4 5 6 0 7 8", "2 1 3 4 5 6 0 7 8"}, errorStep: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := Configure(test.opts); err != nil {
+				t.Fatal(err)
+			}
+			var boards []Board
+			for _, text := range test.boards {
+				boards = append(boards, Board{Rows: 3, Cols: 3, Tiles: splitInts(t, text)})
+			}
+			report := VerifyBoardStates(boards)
+			if report.Valid != test.valid || report.ErrorStep != test.errorStep || report.Length != test.length ||
+				report.Moves != test.moveCount {
+				t.Errorf("got valid %v, step %d, length %d, moves %d (%s)",
+					report.Valid, report.ErrorStep, report.Length, report.Moves, report.Error)
+			}
+		})
+	}
+
+	// The 4x4 states use the same numbering.
+	if err := Configure(Options{}); err != nil {
+		t.Fatal(err)
+	}
+	start := mustBoard(t, "5 1 2 4 9 6 3 8 13 10 7 11 14 0 15 12", 4, 4).State()
+	invalid := start
+	invalid[0][0] = 1
+	if report := VerifyStates([]State{start, start, invalid}); report.ErrorStep != 3 {
+		t.Errorf("invalid third state: ErrorStep = %d, want 3", report.ErrorStep)
+	}
+	if report := VerifyStates([]State{start, start}); report.ErrorStep != 1 {
+		t.Errorf("repeated state: ErrorStep = %d, want 1", report.ErrorStep)
+	}
+}
+
+func mustObstacles(t *testing.T, blocked, walls string, rows, cols int) *Obstacles {
+	t.Helper()
+	o, err := ParseObstacles(blocked, walls, rows, cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+// splitInts reads the numbers of a board without validating them.
+func splitInts(t *testing.T, text string) []int {
+	t.Helper()
+	var values []int
+	for _, field := range strings.Fields(text) {
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, v)
+	}
+	return values
+}
@@ -0,0 +1,462 @@
+package puzzle
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Limits of the walking distance tables that are generated: the counting table used to
+// rank the states and the table itself must fit in memory (5x5 has 65650495 entries).
+const (
+	maxWalkingWays    = 1 << 24
+	maxWalkingEntries = 1 << 27
+)
+
+// walkingSpec identifies a walking distance table for one axis of a board. Along the rows
+// of a board with R rows and C columns there are R lines of C cells (lines=R, width=C);
+// along its columns, C lines of R cells. blank is the line where the goal has the blank.
+//
+// A state of the table is a lines x lines matrix of counts: entry (i, g) is how many tiles
+// whose goal line is g are in line i. Every line holds width tiles except the line of the
+// blank, which holds width-1, and every goal line g holds width tiles except blank. The
+// blank moves to an adjacent line by taking one tile of that line with it. With multi
+// (the multi-tile metric) one move takes the blank any number of lines in the same
+// direction, one tile per line.
+type walkingSpec struct {
+	lines, width, blank int
+	multi               bool
+}
+
+// walkingSpecs returns the specs of the rows and the columns of a board for a goal in the
+// current metric.
+func walkingSpecs(g *Goal) (rows, cols walkingSpec) {
+	multi := moveMetric == MetricMulti
+	rows = walkingSpec{lines: g.Rows, width: g.Cols, blank: g.row(0), multi: multi}
+	cols = walkingSpec{lines: g.Cols, width: g.Rows, blank: g.col(0), multi: multi}
+	return rows, cols
+}
+
+// fileName is the name of the table in Tables.
+func (s walkingSpec) fileName() string {
+	if s.multi {
+		return fmt.Sprintf("wd_%dx%d_b%d_multi.bin", s.lines, s.width, s.blank)
+	}
+	return fmt.Sprintf("wd_%dx%d_b%d.bin", s.lines, s.width, s.blank)
+}
+
+// kind is the kind of the table in the binary container.
+func (s walkingSpec) kind() uint8 {
+	if s.multi {
+		return tableWalkingDistanceMulti
+	}
+	return tableWalkingDistance
+}
+
+// lineSum returns how many tiles line i holds when the blank is in line blank.
+func (s walkingSpec) lineSum(i, blank int) int {
+	if i == blank {
+		return s.width - 1
+	}
+	return s.width
+}
+
+// groupSum returns how many tiles have g as their goal line.
+func (s walkingSpec) groupSum(g int) int {
+	if g == s.blank {
+		return s.width - 1
+	}
+	return s.width
+}
+
+// wdTable is a walking distance table with the ranking of its states. States are ranked by
+// blank line and then cell by cell in row-major order: ways[(b*(n+1)+k)*codes+code] counts
+// the ways to fill the cells k..n-1 of the matrix (n = lines*lines) when the blank is in
+// line b and code encodes, in base width+1, the tiles of each goal line still unplaced.
+type wdTable struct {
+	spec    walkingSpec
+	n       int
+	codes   int
+	powers  []int
+	start   int
+	ways    []uint32
+	offsets []int
+	size    int
+	entries []byte
+}
+
+// newWalkingRanker builds the ranking of the states of spec (without entries). It fails
+// if the table would be too large to generate.
+func newWalkingRanker(spec walkingSpec) (*wdTable, error) {
+	if spec.lines < 1 || spec.width < 1 || spec.blank < 0 || spec.blank >= spec.lines {
+		return nil, fmt.Errorf("invalid walking distance table %+v", spec)
+	}
+	t := &wdTable{spec: spec, n: spec.lines * spec.lines, codes: 1}
+	for g := 0; g < spec.lines; g++ {
+		t.powers = append(t.powers, t.codes)
+		t.start += spec.groupSum(g) * t.codes
+		t.codes *= spec.width + 1
+		if spec.lines*(t.n+1)*t.codes > maxWalkingWays {
+			return nil, fmt.Errorf("walking distance table %dx%d is too large", spec.lines, spec.width)
+		}
+	}
+
+	t.ways = make([]uint32, spec.lines*(t.n+1)*t.codes)
+	rem := make([]int, spec.lines)
+	for b := 0; b < spec.lines; b++ {
+		// after[i] is the number of tiles of the lines after i.
+		after := make([]int, spec.lines+1)
+		for i := spec.lines - 1; i >= 0; i-- {
+			after[i] = after[i+1] + spec.lineSum(i, b)
+		}
+		t.ways[t.at(b, t.n, 0)] = 1
+		for k := t.n - 1; k >= 0; k-- {
+			i, g := k/spec.lines, k%spec.lines
+			for code := 0; code < t.codes; code++ {
+				total := 0
+				for group := range rem {
+					rem[group] = code / t.powers[group] % (spec.width + 1)
+					total += rem[group]
+				}
+				// The tiles left for line i are the ones not needed by the lines after it.
+				rowRem := total - after[i+1]
+				if rowRem < 0 {
+					continue
+				}
+				var count uint64
+				if g == spec.lines-1 {
+					if rowRem <= rem[g] {
+						count = uint64(t.ways[t.at(b, k+1, code-rowRem*t.powers[g])])
+					}
+				} else {
+					for v := 0; v <= rowRem && v <= rem[g]; v++ {
+						count += uint64(t.ways[t.at(b, k+1, code-v*t.powers[g])])
+					}
+				}
+				if count > maxWalkingEntries {
+					// Saturated: a table that uses it is rejected below.
+					count = maxWalkingEntries + 1
+				}
+				t.ways[t.at(b, k, code)] = uint32(count)
+			}
+		}
+		t.offsets = append(t.offsets, t.size)
+		t.size += int(t.ways[t.at(b, 0, t.start)])
+		if t.size > maxWalkingEntries {
+			return nil, fmt.Errorf("walking distance table %dx%d is too large", spec.lines, spec.width)
+		}
+	}
+	return t, nil
+}
+
+// at returns the position of ways for blank line b, cell k and code.
+func (t *wdTable) at(b, k, code int) int {
+	return (b*(t.n+1)+k)*t.codes + code
+}
+
+// rank returns the index of a count matrix (row-major, lines x lines) with the blank in line blank.
+func (t *wdTable) rank(counts []int, blank int) int {
+	index, code := t.offsets[blank], t.start
+	lines := t.spec.lines
+	for k := 0; k < t.n; k++ {
+		g := k % lines
+		v := counts[k]
+		if g != lines-1 {
+			for u := 0; u < v; u++ {
+				index += int(t.ways[t.at(blank, k+1, code-u*t.powers[g])])
+			}
+		}
+		code -= v * t.powers[g]
+	}
+	return index
+}
+
+// unrank is the inverse of rank: it fills counts and returns the blank line.
+func (t *wdTable) unrank(index int, counts []int) int {
+	lines := t.spec.lines
+	blank := lines - 1
+	for b := 1; b < lines; b++ {
+		if index < t.offsets[b] {
+			blank = b - 1
+			break
+		}
+	}
+	index -= t.offsets[blank]
+	code, rowRem := t.start, 0
+	for k := 0; k < t.n; k++ {
+		i, g := k/lines, k%lines
+		if g == 0 {
+			rowRem = t.spec.lineSum(i, blank)
+		}
+		v := rowRem
+		if g != lines-1 {
+			for v = 0; ; v++ {
+				ways := int(t.ways[t.at(blank, k+1, code-v*t.powers[g])])
+				if index < ways {
+					break
+				}
+				index -= ways
+			}
+		}
+		counts[k] = v
+		rowRem -= v
+		code -= v * t.powers[g]
+	}
+	return blank
+}
+
+// lookup returns the walking distance of a count matrix; blank is its blank line.
+func (t *wdTable) lookup(counts []int, blank int) int {
+	return int(t.entries[t.rank(counts, blank)])
+}
+
+// lookupMatrix returns the walking distance of a count matrix given as a slice of lines; the
+// blank is in the line that holds one tile less.
+func (t *wdTable) lookupMatrix(matrix [][]int) int {
+	counts := make([]int, 0, t.n)
+	blank := 0
+	for i, line := range matrix {
+		sum := 0
+		for _, count := range line {
+			sum += count
+		}
+		if sum < t.spec.width {
+			blank = i
+		}
+		counts = append(counts, line...)
+	}
+	return t.lookup(counts, blank)
+}
+
+// lookupPacked returns the walking distance of a 4x4 count matrix packed as in countShift.
+func (t *wdTable) lookupPacked(packed uint64) int {
+	var counts [16]int
+	blank := 0
+	for row := 0; row < 4; row++ {
+		sum := 0
+		for group := 0; group < 4; group++ {
+			counts[row*4+group] = int(packed >> countShift(row, group) & 7)
+			sum += counts[row*4+group]
+		}
+		if sum == 3 {
+			blank = row
+		}
+	}
+	return t.lookup(counts[:], blank)
+}
+
+// buildWalkingTable runs a breadth-first search from the goal matrix over every state of
+// spec and returns the table with the distances. In the multi-tile metric every level
+// chains single steps in the same direction (see walkingSteps).
+func buildWalkingTable(spec walkingSpec) (*wdTable, error) {
+	t, err := newWalkingRanker(spec)
+	if err != nil {
+		return nil, err
+	}
+	t.entries = make([]byte, t.size)
+	for i := range t.entries {
+		t.entries[i] = tableUnreachable
+	}
+
+	lines := spec.lines
+	counts := make([]int, t.n)
+	for i := 0; i < lines; i++ {
+		counts[i*lines+i] = spec.groupSum(i)
+	}
+	start := t.rank(counts, spec.blank)
+	t.entries[start] = 0
+	next := []uint32{uint32(start)}
+	var seen []uint64
+	for distance := 1; len(next) > 0; distance++ {
+		if distance >= tableUnreachable {
+			return nil, fmt.Errorf("walking distance table %dx%d has distances above %d", spec.lines, spec.width, tableUnreachable-1)
+		}
+		current := next
+		next = nil
+		if !spec.multi {
+			t.walkingSteps(current, 0, counts, func(neighbor int) {
+				if t.entries[neighbor] == tableUnreachable {
+					t.entries[neighbor] = byte(distance)
+					next = append(next, uint32(neighbor))
+				}
+			})
+			continue
+		}
+		// A multi-tile move is a chain of steps in one direction: every state reached
+		// after 1, 2, ... steps is one move away. seen avoids following a state twice.
+		if seen == nil {
+			seen = make([]uint64, (t.size+63)/64)
+		}
+		for _, direction := range [2]int{-1, 1} {
+			for i := range seen {
+				seen[i] = 0
+			}
+			chain := current
+			for len(chain) > 0 {
+				var following []uint32
+				t.walkingSteps(chain, direction, counts, func(neighbor int) {
+					if seen[neighbor/64]&(1<<(neighbor%64)) != 0 {
+						return
+					}
+					seen[neighbor/64] |= 1 << (neighbor % 64)
+					following = append(following, uint32(neighbor))
+					if t.entries[neighbor] == tableUnreachable {
+						t.entries[neighbor] = byte(distance)
+						next = append(next, uint32(neighbor))
+					}
+				})
+				chain = following
+			}
+		}
+	}
+	return t, nil
+}
+
+// walkingSteps calls visit with every state one step away from the states of indices: the
+// blank moves to an adjacent line (only in direction, -1 or 1, unless it is 0) taking one of
+// its tiles, of any goal line. counts is scratch space.
+func (t *wdTable) walkingSteps(indices []uint32, direction int, counts []int, visit func(int)) {
+	lines := t.spec.lines
+	for _, index := range indices {
+		blank := t.unrank(int(index), counts)
+		for _, source := range [2]int{blank - 1, blank + 1} {
+			if source < 0 || source >= lines || (direction != 0 && source != blank+direction) {
+				continue
+			}
+			for g := 0; g < lines; g++ {
+				if counts[source*lines+g] == 0 {
+					continue
+				}
+				counts[source*lines+g]--
+				counts[blank*lines+g]++
+				visit(t.rank(counts, source))
+				counts[source*lines+g]++
+				counts[blank*lines+g]--
+			}
+		}
+	}
+}
+
+var (
+	// wdTables caches the walking distance tables loaded by loadWalkingSpec.
+	wdTables   = map[walkingSpec]*wdTable{}
+	wdTablesMu sync.Mutex
+
+	// wdRowTable and wdColTable are the tables of the rows and the columns of the goal
+	// of the last prepareWalkingTables. For a 4x4 goal in the single-tile metric they only
+	// cover the axes whose blank ends in a middle line; the other axes use walkingTable.
+	wdRowTable, wdColTable *wdTable
+)
+
+// prepareWalkingTables generates the walking distance tables of g when they are missing
+// and loads them into wdRowTable and wdColTable. An axis too large to generate (6x6 and
+// up) is left without table.
+func prepareWalkingTables(g *Goal) error {
+	rows, cols := walkingSpecs(g)
+	var err error
+	wdRowTable, wdColTable = nil, nil
+	legacy := g.is4x4() && moveMetric == MetricSingle && obstaclesFor(4, 4) == nil
+	if !legacy || !walkingRows {
+		if wdRowTable, err = prepareWalkingSpec(rows); err != nil {
+			return err
+		}
+	}
+	if !legacy || !walkingCols {
+		if wdColTable, err = prepareWalkingSpec(cols); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepareWalkingSpec generates (if needed) and loads the table of spec, or returns nil if
+// spec is too large.
+func prepareWalkingSpec(spec walkingSpec) (*wdTable, error) {
+	if !walkingSupported(spec) {
+		logf("La tabla de walking distance %dx%d es demasiado grande, no se usará.\n", spec.lines, spec.width)
+		return nil, nil
+	}
+	if err := generateWalkingTable(spec); err != nil {
+		return nil, err
+	}
+	t, err := loadWalkingSpec(spec)
+	if err != nil {
+		return nil, fmt.Errorf("error loading walking distance table: %w", err)
+	}
+	return t, nil
+}
+
+// goalWalkingTables returns the tables of wdRowTable and wdColTable that belong to g (nil
+// for an axis without table).
+func goalWalkingTables(g *Goal) (rowTable, colTable *wdTable) {
+	rows, cols := walkingSpecs(g)
+	if wdRowTable != nil && wdRowTable.spec == rows {
+		rowTable = wdRowTable
+	}
+	if wdColTable != nil && wdColTable.spec == cols {
+		colTable = wdColTable
+	}
+	return rowTable, colTable
+}
+
+// generateWalkingTable generates the walking distance table of spec in Tables if it does
+// not exist yet, like GenerateMovingDistances.
+func generateWalkingTable(spec walkingSpec) error {
+	name := spec.fileName()
+	if tables.has(name) || tables.hasFallback(name) {
+		return nil
+	}
+	if tables.NoGenerate {
+		return tables.missing("la tabla de walking distance", name)
+	}
+	metric := ""
+	if spec.multi {
+		metric = ", métrica multi"
+	}
+	logf("Generando la tabla de walking distance %dx%d (espacio vacío en la línea %d%s) en %s...\n",
+		spec.lines, spec.width, spec.blank, metric, tables.path(name))
+	t, err := buildWalkingTable(spec)
+	if err != nil {
+		return err
+	}
+	data := encodeTable(spec.kind(), spec.lines, spec.width, t.entries)
+	if err := tables.store(name, data); err != nil {
+		return err
+	}
+	// Share the entries with the stored table instead of keeping two copies.
+	t.entries = data[len(data)-len(t.entries):]
+	wdTablesMu.Lock()
+	wdTables[spec] = t
+	wdTablesMu.Unlock()
+	return nil
+}
+
+// loadWalkingSpec returns the walking distance table of spec, reading it from Tables the
+// first time.
+func loadWalkingSpec(spec walkingSpec) (*wdTable, error) {
+	wdTablesMu.Lock()
+	defer wdTablesMu.Unlock()
+	if t, ok := wdTables[spec]; ok {
+		return t, nil
+	}
+	t, err := newWalkingRanker(spec)
+	if err != nil {
+		return nil, err
+	}
+	name := spec.fileName()
+	data, err := tables.readAny(name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %v", err)
+	}
+	t.entries, err = decodeTable(data, spec.kind(), spec.lines, spec.width, t.size)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", tables.path(name), err)
+	}
+	wdTables[spec] = t
+	return t, nil
+}
+
+// walkingSupported reports whether the table of spec is small enough to be generated.
+func walkingSupported(spec walkingSpec) bool {
+	_, err := newWalkingRanker(spec)
+	return err == nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"fifteen/puzzle"
+)
+
+// Output formats accepted by -format.
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// solveReport is the JSON document written by -format json for a single puzzle.
+type solveReport struct {
+	Input      *puzzle.Board           `json:"input,omitempty"`
+	Solvable   bool                    `json:"solvable"`
+	Heuristic  *puzzle.HeuristicReport `json:"heuristic,omitempty"`
+	Algorithm  string                  `json:"algorithm,omitempty"`
+	Solved     bool                    `json:"solved"`
+	Quality    string                  `json:"quality,omitempty"`
+	Stopped    string                  `json:"stopped,omitempty"`
+	Iterations []puzzle.IterationInfo  `json:"iterations,omitempty"`
+	LastBound  int                     `json:"last_bound,omitempty"`
+	LowerBound int                     `json:"lower_bound,omitempty"`
+	Improved   []puzzle.Improvement    `json:"improvements,omitempty"`
+	Notation   string                  `json:"notation,omitempty"`
+	Metric     string                  `json:"metric,omitempty"`
+	Solution   string                  `json:"solution,omitempty"`
+	Moves      []string                `json:"moves"`
+	Length     int                     `json:"length"`
+	Path       []puzzle.Board          `json:"path"`
+	BestPath   []puzzle.Board          `json:"best_partial_path,omitempty"`
+	BestMoves  string                  `json:"best_partial_moves,omitempty"`
+	BestH      *int                    `json:"best_partial_h,omitempty"`
+	Generated  int                     `json:"generated"`
+	Expanded   int                     `json:"expanded"`
+	Timings    reportTimings           `json:"timings"`
+	Error      string                  `json:"error,omitempty"`
+}
+
+// reportTimings holds the durations of a run in milliseconds.
+type reportTimings struct {
+	SolveMs float64 `json:"solve_ms"`
+	TotalMs float64 `json:"total_ms"`
+}
+
+// milliseconds converts a duration into fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// newSolveReport builds the report of a solved (or attempted) puzzle.
+func newSolveReport(initial puzzle.Board, solvable bool, heuristic puzzle.HeuristicReport, result puzzle.Result, start time.Time) solveReport {
+	report := solveReport{
+		Input:      &initial,
+		Solvable:   solvable,
+		Heuristic:  &heuristic,
+		Algorithm:  result.Algorithm,
+		Solved:     result.Solved,
+		Quality:    result.Quality,
+		Stopped:    result.Stopped,
+		Iterations: result.Iterations,
+		LastBound:  result.Bound,
+		LowerBound: result.LowerBound,
+		Improved:   result.Improvements,
+		Moves:      []string{},
+		Path:       result.BoardPath,
+		Generated:  result.Generated,
+		Expanded:   result.Expanded,
+	}
+	if result.Solved {
+		report.Notation = notation
+		report.Metric = options.Metric
+		report.Solution = puzzle.FormatMetricMoves(result.Moves, notation, options.Metric)
+		report.Length = result.Length
+		for _, m := range result.Moves {
+			report.Moves = append(report.Moves, m.String())
+		}
+	}
+	if !result.Solved && len(result.BestBoardPath) > 0 {
+		moves, _ := puzzle.BoardMoves(result.BestBoardPath)
+		report.BestPath = result.BestBoardPath
+		report.BestMoves = puzzle.FormatMoves(moves, notation)
+		report.BestH = &result.BestH
+	}
+	if report.Path == nil {
+		report.Path = []puzzle.Board{}
+	}
+	report.Timings = reportTimings{SolveMs: milliseconds(result.Elapsed), TotalMs: milliseconds(time.Since(start))}
+	return report
+}
+
+// writeJSONReport writes the report as an indented JSON document to stdout.
+func writeJSONReport(report solveReport) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// runJSON reads a puzzle from args (or, if empty, the first line of stdin), solves it
+// without printing any progress and writes a single JSON document with the input, the
+// solvability verdict, the heuristic components, the IDA* iterations, the moves, the path,
+// the statistics and the timings. The written report is returned so the caller can choose
+// the exit code.
+func runJSON(ctx context.Context, args []string) (solveReport, error) {
+	start := time.Now()
+	quiet = true
+
+	initial, err := readPuzzle(args, false)
+	if err != nil {
+		report := solveReport{Moves: []string{}, Path: []puzzle.Board{}, Error: err.Error()}
+		return report, writeJSONReport(report)
+	}
+
+	if err := prepareTables(); err != nil {
+		report := solveReport{Input: &initial, Moves: []string{}, Path: []puzzle.Board{}, Error: err.Error()}
+		return report, writeJSONReport(report)
+	}
+
+	heuristic := initial.HeuristicReport()
+	result, err := puzzle.Solve(ctx, initial, options)
+	solvable := !errors.Is(err, puzzle.ErrUnsolvable)
+	if err != nil && solvable {
+		report := solveReport{Input: &initial, Moves: []string{}, Path: []puzzle.Board{}, Error: err.Error()}
+		return report, writeJSONReport(report)
+	}
+	report := newSolveReport(initial, solvable, heuristic, result, start)
+	return report, writeJSONReport(report)
+}
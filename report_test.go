@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"fifteen/puzzle"
+)
+
+// runJSONDocument runs runJSON on a puzzle and decodes the document it writes.
+func runJSONDocument(t *testing.T, input string) (solveReport, map[string]interface{}) {
+	t.Helper()
+	var report solveReport
+	var err error
+	out := captureOutput(t, func() {
+		report, err = runJSON(context.Background(), strings.Fields(input))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &document); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	return report, document
+}
+
+// checkKeys fails the test if document lacks one of want or has one of notWant.
+func checkKeys(t *testing.T, document map[string]interface{}, want, notWant []string) {
+	t.Helper()
+	for _, key := range want {
+		if _, ok := document[key]; !ok {
+			t.Errorf("missing %q in %v", key, document)
+		}
+	}
+	for _, key := range notWant {
+		if _, ok := document[key]; ok {
+			t.Errorf("unexpected %q in %v", key, document)
+		}
+	}
+}
+
+func TestJSONReportSolved(t *testing.T) {
+	setCLIOptions(t, puzzle.Options{Heuristic: puzzle.HeuristicAdmissible})
+	report, document := runJSONDocument(t, "1 2 3 4 5 6 7 8 9 10 11 12 13 0 14 15")
+	checkKeys(t, document, []string{"input", "solvable", "heuristic", "algorithm", "solved", "quality",
+		"iterations", "notation", "metric", "solution", "moves", "length", "path", "generated", "expanded", "timings"},
+		[]string{"stopped", "error", "best_partial_path", "best_partial_moves", "best_partial_h"})
+	if reportExitCode(report) != exitOK {
+		t.Errorf("exit code %d", reportExitCode(report))
+	}
+
+	var got struct {
+		Input     [][]int `json:"input"`
+		Solvable  bool    `json:"solvable"`
+		Heuristic struct {
+			Formula   string `json:"formula"`
+			Manhattan *int   `json:"manhattan"`
+			Total     int    `json:"total"`
+		} `json:"heuristic"`
+		Solved     bool                   `json:"solved"`
+		Iterations []puzzle.IterationInfo `json:"iterations"`
+		Solution   string                 `json:"solution"`
+		Moves      []string               `json:"moves"`
+		Length     int                    `json:"length"`
+		Path       [][][]int              `json:"path"`
+		Generated  int                    `json:"generated"`
+		Timings    map[string]float64     `json:"timings"`
+	}
+	data, _ := json.Marshal(document)
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Input) != 4 || len(got.Input[3]) != 4 || got.Input[3][1] != 0 {
+		t.Errorf("input %v", got.Input)
+	}
+	if !got.Solvable || !got.Solved || got.Length != 2 || got.Solution != "RR" || strings.Join(got.Moves, ",") != "Right,Right" {
+		t.Errorf("solvable %v, solved %v, length %d, solution %q, moves %v", got.Solvable, got.Solved, got.Length, got.Solution, got.Moves)
+	}
+	if got.Heuristic.Formula == "" || got.Heuristic.Manhattan == nil || *got.Heuristic.Manhattan != 2 || got.Heuristic.Total != 2 {
+		t.Errorf("heuristic %+v", got.Heuristic)
+	}
+	if len(got.Iterations) == 0 || got.Iterations[0].Bound != 2 {
+		t.Errorf("iterations %+v", got.Iterations)
+	}
+	if len(got.Path) != 3 || got.Path[2][3][3] != 0 {
+		t.Errorf("path %v", got.Path)
+	}
+	if got.Generated == 0 {
+		t.Error("no generated states")
+	}
+	if _, ok := got.Timings["solve_ms"]; !ok {
+		t.Errorf("timings %v", got.Timings)
+	}
+	if _, ok := got.Timings["total_ms"]; !ok {
+		t.Errorf("timings %v", got.Timings)
+	}
+}
+
+func TestJSONReportNotSolved(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opts    puzzle.Options
+		code    int
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "unsolvable",
+			input:   "2 1 3 4 5 6 7 8 9 10 11 12 13 14 15 0",
+			code:    exitUnsolvable,
+			want:    []string{"input", "solvable", "solved", "moves", "path"},
+			notWant: []string{"error", "solution", "quality"},
+		},
+		{
+			name:    "stopped",
+			input:   "5 1 2 4 9 6 3 8 13 10 7 11 14 0 15 12",
+			opts:    puzzle.Options{MaxNodes: 5},
+			code:    exitNotSolved,
+			want:    []string{"input", "solvable", "solved", "stopped", "best_partial_path", "best_partial_moves", "best_partial_h"},
+			notWant: []string{"error", "solution", "quality"},
+		},
+		{
+			name:    "invalid",
+			input:   "1 2 3",
+			code:    exitError,
+			want:    []string{"error", "moves", "path"},
+			notWant: []string{"input", "solution"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setCLIOptions(t, test.opts)
+			report, document := runJSONDocument(t, test.input)
+			checkKeys(t, document, test.want, test.notWant)
+			if code := reportExitCode(report); code != test.code {
+				t.Errorf("exit code %d, want %d", code, test.code)
+			}
+			if document["solved"] != false {
+				t.Errorf("solved = %v", document["solved"])
+			}
+			// moves and path are always arrays, even when empty.
+			if moves, ok := document["moves"].([]interface{}); !ok || len(moves) != 0 {
+				t.Errorf("moves = %v", document["moves"])
+			}
+			if path, ok := document["path"].([]interface{}); !ok || len(path) != 0 {
+				t.Errorf("path = %v", document["path"])
+			}
+		})
+	}
+}